@@ -6,11 +6,10 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/nguyengg/golambda/configsupport"
-	"github.com/nguyengg/golambda/metrics"
 	"github.com/nguyengg/golambda/start"
-	"github.com/rs/zerolog"
+	"github.com/nguyengg/golambda/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"log"
-	"os"
 )
 
 // HandlerV2 for API Gateway HTTP Lambda authorizer requests using V2 payload request and response format.
@@ -18,13 +17,19 @@ type HandlerV2 func(context.Context, events.APIGatewayV2CustomAuthorizerV2Reques
 
 // StartV2 the Lambda runtime loop.
 func StartV2(handler HandlerV2, options ...start.Option) {
+	lambda.Start(WrapV2(handler, options...))
+}
+
+// WrapV2 returns the handler that StartV2 passes to the Lambda runtime, which is the given handler wrapped with
+// metrics, logging, and middlewares according to the options.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. in tests.
+func WrapV2(handler HandlerV2, options ...start.Option) HandlerV2 {
 	opts := start.New(options)
+	h := start.Chain(opts, start.Handler[events.APIGatewayV2CustomAuthorizerV2Request, events.APIGatewayV2CustomAuthorizerSimpleResponse](handler))
 
-	lambda.Start(func(ctx context.Context, request events.APIGatewayV2CustomAuthorizerV2Request) (response events.APIGatewayV2CustomAuthorizerSimpleResponse, err error) {
-		m := metrics.NewSimpleMetricsContext(
-			zerolog.New(os.Stderr).WithContext(ctx),
-			request.RequestContext.RequestID,
-			request.RequestContext.TimeEpoch)
+	return func(ctx context.Context, request events.APIGatewayV2CustomAuthorizerV2Request) (response events.APIGatewayV2CustomAuthorizerSimpleResponse, err error) {
+		m := opts.NewMetrics(ctx, request.RequestContext.RequestID, request.RequestContext.TimeEpoch)
 		ctx = m.WithContext(ctx)
 
 		if !opts.DisableRequestDebugLogging && configsupport.IsDebug() {
@@ -49,6 +54,16 @@ func StartV2(handler HandlerV2, options ...start.Option) {
 
 		panicked := true
 
+		ctx, span := opts.StartSpan(ctx, "apigatewayhttpapi/auth",
+			attribute.String("path", request.RequestContext.HTTP.Path),
+			attribute.String("method", request.RequestContext.HTTP.Method),
+			attribute.String("stage", request.RequestContext.Stage),
+			attribute.String("routeKey", request.RequestContext.RouteKey))
+		defer func() {
+			span.SetAttributes(attribute.Bool("isAuthorized", response.IsAuthorized))
+			tracing.End(span, err, panicked)
+		}()
+
 		if !opts.DisableMetricsLogging {
 			m.
 				SetProperty("path", request.RequestContext.HTTP.Path).
@@ -78,8 +93,16 @@ func StartV2(handler HandlerV2, options ...start.Option) {
 			}()
 		}
 
-		response, err = handler(ctx, request)
+		if opts.RecoverPanics {
+			defer func() {
+				if r := recover(); r != nil {
+					err = opts.Recover(ctx, r)
+				}
+			}()
+		}
+
+		response, err = h(ctx, request)
 		panicked = false
 		return
-	})
+	}
 }
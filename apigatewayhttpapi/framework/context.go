@@ -0,0 +1,99 @@
+package framework
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	v2 "github.com/nguyengg/golambda/apigatewayhttpapi"
+	"github.com/nguyengg/golambda/httpcontext"
+	"github.com/nguyengg/golambda/lambdafunctionurl"
+	"github.com/nguyengg/golambda/start"
+	"net/http"
+)
+
+// Context is the context passed into the wrapped handler of API Gateway HTTP API requests.
+//
+// Context implements httpcontext.Context, so handlers that don't need the original events.APIGatewayV2HTTPRequest can
+// depend on httpcontext.Context instead and also be deployed behind a Lambda Function URL (see httpcontext.Adapt).
+type Context struct {
+	functionURLContext
+	request *events.APIGatewayV2HTTPRequest
+
+	// header and response are only used by the deprecated AddResponseHeader and Response.
+	header   http.Header
+	response *events.APIGatewayV2HTTPResponse
+}
+
+var _ httpcontext.Context = &Context{}
+
+// Start starts the Lambda runtime loop with the wrapped handler.
+func Start(handler func(*Context) error, options ...start.Option) {
+	v2.Start(NewHandler(handler), options...)
+}
+
+// NewHandler adapts the wrapped handler as a [v2.Handler].
+//
+// API Gateway HTTP API and Lambda Function URL share the same payload format version 2.0, so the request is converted
+// to an events.LambdaFunctionURLRequest to be handled by the same implementation as lambdafunctionurl.Context in
+// BUFFERED mode. The authorizer and other API Gateway-specific fields are only available from Context.Request.
+func NewHandler(handler func(*Context) error) v2.Handler {
+	return func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		res, err := lambdafunctionurl.NewHandler(func(fc lambdafunctionurl.Context) error {
+			c := &Context{functionURLContext: fc, request: &req}
+			err := handler(c)
+			if applyErr := c.applyResponse(); applyErr != nil && err == nil {
+				err = applyErr
+			}
+			return err
+		})(ctx, toFunctionURLRequest(&req))
+
+		return events.APIGatewayV2HTTPResponse{
+			StatusCode:        res.StatusCode,
+			Headers:           res.Headers,
+			MultiValueHeaders: map[string][]string{},
+			Body:              res.Body,
+			IsBase64Encoded:   res.IsBase64Encoded,
+			Cookies:           res.Cookies,
+		}, err
+	}
+}
+
+// functionURLContext is embedded by Context under a name that doesn't collide with the Context method.
+type functionURLContext = lambdafunctionurl.Context
+
+// Request returns the original events.APIGatewayV2HTTPRequest instance.
+func (c *Context) Request() *events.APIGatewayV2HTTPRequest {
+	return c.request
+}
+
+// PathParam returns the path parameter value for the specified key.
+func (c *Context) PathParam(key string) string {
+	return c.request.PathParameters[key]
+}
+
+// StageVariable returns the stage variable for the specified key.
+func (c *Context) StageVariable(key string) string {
+	return c.request.StageVariables[key]
+}
+
+func toFunctionURLRequest(req *events.APIGatewayV2HTTPRequest) events.LambdaFunctionURLRequest {
+	return events.LambdaFunctionURLRequest{
+		Version:               req.Version,
+		RawPath:               req.RawPath,
+		RawQueryString:        req.RawQueryString,
+		Cookies:               req.Cookies,
+		Headers:               req.Headers,
+		QueryStringParameters: req.QueryStringParameters,
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			AccountID:    req.RequestContext.AccountID,
+			RequestID:    req.RequestContext.RequestID,
+			APIID:        req.RequestContext.APIID,
+			DomainName:   req.RequestContext.DomainName,
+			DomainPrefix: req.RequestContext.DomainPrefix,
+			Time:         req.RequestContext.Time,
+			TimeEpoch:    req.RequestContext.TimeEpoch,
+			HTTP:         events.LambdaFunctionURLRequestContextHTTPDescription(req.RequestContext.HTTP),
+		},
+		Body:            req.Body,
+		IsBase64Encoded: req.IsBase64Encoded,
+	}
+}
@@ -0,0 +1,137 @@
+package framework
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/httpcontext"
+	"github.com/nguyengg/golambda/lambdafunctionurl"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewHandler(t *testing.T) {
+	// the same handler is deployed behind both API Gateway HTTP API and Lambda Function URL.
+	handle := func(c httpcontext.Context) error {
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := c.UnmarshalRequestBodyWithOpts(&body, httpcontext.DisallowUnknownFields); err != nil {
+			return c.RespondBadRequest("invalid body: %v", err)
+		}
+
+		if err := c.SetCookie(http.Cookie{Name: "session", Value: c.RequestCookie("session")}); err != nil {
+			return err
+		}
+		return c.RespondOKWithText(c.RequestMethod() + " " + c.RequestPath() + " " + body.Name)
+	}
+
+	t.Run("framework", func(t *testing.T) {
+		response, err := NewHandler(func(c *Context) error {
+			if got := c.PathParam("id"); got != "123" {
+				t.Errorf("PathParam() = %q, want %q", got, "123")
+			}
+			if got := c.Request().RouteKey; got != "POST /items/{id}" {
+				t.Errorf("RouteKey = %q, want %q", got, "POST /items/{id}")
+			}
+			return handle(c)
+		})(context.Background(), events.APIGatewayV2HTTPRequest{
+			RouteKey:       "POST /items/{id}",
+			Cookies:        []string{"session=abc"},
+			PathParameters: map[string]string{"id": "123"},
+			RequestContext: events.APIGatewayV2HTTPRequestContext{
+				HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: "POST", Path: "/items/123"},
+			},
+			Body: `{"name":"test"}`,
+		})
+		if err != nil {
+			t.Fatalf("handler error = %v", err)
+		}
+		if response.StatusCode != http.StatusOK || response.Body != "POST /items/123 test" {
+			t.Errorf("response = (%d, %q), want (200, %q)", response.StatusCode, response.Body, "POST /items/123 test")
+		}
+		if len(response.Cookies) != 1 || response.Cookies[0] != "session=abc" {
+			t.Errorf("Cookies = %v, want [session=abc]", response.Cookies)
+		}
+	})
+
+	t.Run("lambdafunctionurl", func(t *testing.T) {
+		response, err := lambdafunctionurl.NewHandler(httpcontext.Adapt[lambdafunctionurl.Context](handle))(context.Background(), events.LambdaFunctionURLRequest{
+			Cookies: []string{"session=abc"},
+			RequestContext: events.LambdaFunctionURLRequestContext{
+				HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: "POST", Path: "/items/123"},
+			},
+			Body: `{"name":"test"}`,
+		})
+		if err != nil {
+			t.Fatalf("handler error = %v", err)
+		}
+		if response.StatusCode != http.StatusOK || response.Body != "POST /items/123 test" {
+			t.Errorf("response = (%d, %q), want (200, %q)", response.StatusCode, response.Body, "POST /items/123 test")
+		}
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		response, err := NewHandler(httpcontext.Adapt[*Context](handle))(context.Background(), events.APIGatewayV2HTTPRequest{
+			RequestContext: events.APIGatewayV2HTTPRequestContext{
+				HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: "POST", Path: "/"},
+			},
+			Body: `{"unknown":true}`,
+		})
+		if err != nil {
+			t.Fatalf("handler error = %v", err)
+		}
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("StatusCode = %d, want %d", response.StatusCode, http.StatusBadRequest)
+		}
+	})
+}
+
+type cachingItem struct{}
+
+func (cachingItem) ETag() *ETag {
+	e := NewStrongETag("abc")
+	return &e
+}
+
+func (cachingItem) LastModified() *time.Time {
+	return nil
+}
+
+func TestNewHandler_deprecated(t *testing.T) {
+	// handlers written against the original *Context must keep working.
+	response, err := NewHandler(func(c *Context) error {
+		if c.Method() != "PUT" || c.Path() != "/items/123" {
+			t.Errorf("Method() Path() = %q %q, want PUT /items/123", c.Method(), c.Path())
+		}
+		if ifMatch, err := c.ParseIfMatchHeader(); err != nil || ifMatch == nil || !ifMatch.MatchStrong(NewStrongETag("abc")) {
+			t.Errorf("ParseIfMatchHeader() = (%v, %v)", ifMatch, err)
+		}
+
+		c.SetResponseHeader("Vary", "Origin")
+		c.AddResponseHeader("Vary", "Accept-Encoding").SetCacheControlMaxAge(time.Minute)
+		c.SetResponseCachingHeaders(cachingItem{})
+		c.Response().Cookies = []string{"session=abc"}
+		return c.RespondMessage(http.StatusAccepted, "queued")
+	})(context.Background(), events.APIGatewayV2HTTPRequest{
+		Headers: map[string]string{"if-match": `"abc"`},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: "PUT", Path: "/items/123"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+
+	if response.StatusCode != http.StatusAccepted || response.Body != `{"status":202,"message":"queued"}` {
+		t.Errorf("response = (%d, %q)", response.StatusCode, response.Body)
+	}
+	for k, want := range map[string]string{"Vary": "Origin, Accept-Encoding", "Cache-Control": "max-age=60", "Etag": `"abc"`} {
+		if got := response.Headers[k]; got != want {
+			t.Errorf("Headers[%s] = %q, want %q", k, got, want)
+		}
+	}
+	if len(response.Cookies) != 1 || response.Cookies[0] != "session=abc" {
+		t.Errorf("Cookies = %v, want [session=abc]", response.Cookies)
+	}
+}
@@ -0,0 +1,164 @@
+package framework
+
+import (
+	"encoding/base64"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/lambdafunctionurl/cachecontrol"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WithResponseCachingHeaders allows implement types to generate ETag and Last-Modified response headers for caching
+// purposes.
+//
+// Deprecated: implement httpcontext.HasETag and httpcontext.HasLastModified instead.
+type WithResponseCachingHeaders interface {
+	ETag() *ETag
+	LastModified() *time.Time
+}
+
+// SetResponseCachingHeaders adds ETag and Last-Modified headers to the response.
+//
+// The value can implement either the deprecated WithResponseCachingHeaders, or httpcontext.HasETag and
+// httpcontext.HasLastModified like [httpcontext.Context.SetResponseCachingHeaders].
+func (c *Context) SetResponseCachingHeaders(v interface{}) (set bool) {
+	i, ok := v.(WithResponseCachingHeaders)
+	if !ok {
+		return c.functionURLContext.SetResponseCachingHeaders(v)
+	}
+
+	if e := i.ETag(); e != nil {
+		c.SetResponseHeader("ETag", e.String())
+		set = true
+	}
+	if t := i.LastModified(); t != nil {
+		c.SetResponseHeader("Last-Modified", t.Format(http.TimeFormat))
+		set = true
+	}
+
+	return
+}
+
+// SetResponseHeader is used to modify a response header.
+func (c *Context) SetResponseHeader(key, value string) {
+	if c.header == nil {
+		c.header = http.Header{}
+	}
+	c.header.Set(key, value)
+
+	c.functionURLContext.SetResponseHeader(key, value)
+}
+
+// AddResponseHeader is used to add to a response header.
+//
+// The values are joined with ", " to the values that have been set with SetResponseHeader or AddResponseHeader. Headers
+// that are set by other methods, such as "Content-Type" by RespondOKWithJSON, are replaced instead.
+//
+// Deprecated: use SetResponseHeader.
+func (c *Context) AddResponseHeader(key, value string) *Context {
+	if c.header == nil {
+		c.header = http.Header{}
+	}
+	c.header.Add(key, value)
+
+	c.functionURLContext.SetResponseHeader(key, strings.Join(c.header.Values(key), ", "))
+	return c
+}
+
+// SetCacheControlMaxAge Sets the response Cache-Control header.
+//
+// Deprecated: use SetCacheControl with cachecontrol.MaxAge.
+func (c *Context) SetCacheControlMaxAge(duration time.Duration) *Context {
+	c.SetCacheControl(cachecontrol.MaxAge(duration))
+	return c
+}
+
+// Method returns the HTTP method of the request.
+//
+// Deprecated: use RequestMethod.
+func (c *Context) Method() string {
+	return c.RequestMethod()
+}
+
+// Path returns the HTTP path of the request.
+//
+// Deprecated: use RequestPath.
+func (c *Context) Path() string {
+	return c.RequestPath()
+}
+
+// ParseIfMatchHeader parses and returns the If-Match request header.
+//
+// Deprecated: use ParseIfMatch.
+func (c *Context) ParseIfMatchHeader() (*IfMatch, error) {
+	return c.ParseIfMatch()
+}
+
+// Respond sets the response's status code and a generated response describing that status.
+//
+// Deprecated: use RespondFormattedStatus.
+func (c *Context) Respond(statusCode int) error {
+	return c.RespondFormattedStatus(statusCode)
+}
+
+// RespondMessage is a variant of Respond that allows a custom message.
+//
+// Deprecated: use RespondFormatted.
+func (c *Context) RespondMessage(statusCode int, message string) error {
+	return c.RespondFormatted(statusCode, "%s", message)
+}
+
+// Response returns an events.APIGatewayV2HTTPResponse instance that can be modified to return contents back to caller.
+//
+// The returned response starts empty and does not reflect changes made by other methods. After the handler returns,
+// its non-zero status code and body replace the current ones, and its headers and cookies are added.
+//
+// Deprecated: use the SetStatusCode, SetResponseHeader, SetCookie, and Respond methods instead.
+func (c *Context) Response() *events.APIGatewayV2HTTPResponse {
+	if c.response == nil {
+		c.response = &events.APIGatewayV2HTTPResponse{}
+	}
+
+	return c.response
+}
+
+// applyResponse applies the changes made to the response returned by Response.
+func (c *Context) applyResponse() error {
+	r := c.response
+	if r == nil {
+		return nil
+	}
+
+	if r.Body != "" {
+		if !r.IsBase64Encoded {
+			if err := c.RespondWithText(r.Body); err != nil {
+				return err
+			}
+		} else if data, err := base64.StdEncoding.DecodeString(r.Body); err != nil {
+			return err
+		} else if err = c.RespondWithBase64Data(data); err != nil {
+			return err
+		}
+	}
+	if r.StatusCode != 0 {
+		c.SetStatusCode(r.StatusCode)
+	}
+	for k, v := range r.Headers {
+		c.SetResponseHeader(k, v)
+	}
+	for k, vs := range r.MultiValueHeaders {
+		c.SetResponseHeader(k, strings.Join(vs, ", "))
+	}
+	for _, v := range r.Cookies {
+		cookie, err := http.ParseSetCookie(v)
+		if err != nil {
+			return err
+		}
+		if err = c.SetCookie(*cookie); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package framework
+
+import (
+	"github.com/nguyengg/golambda/lambdafunctionurl/etag"
+)
+
+// ETag is an alias of etag.ETag.
+//
+// Deprecated: use etag.ETag.
+type ETag = etag.ETag
+
+// IfMatch is an alias of etag.Directives.
+//
+// Deprecated: use etag.Directives from [httpcontext.Context.ParseIfMatch].
+type IfMatch = etag.Directives
+
+// NewStrongETag returns an ETag with ETag.Weak set to false.
+//
+// Deprecated: use etag.NewStrongETag.
+func NewStrongETag(value string) ETag {
+	return etag.NewStrongETag(value)
+}
+
+// NewWeakETag returns an ETag with ETag.Weak set to true.
+//
+// Deprecated: use etag.NewWeakETag.
+func NewWeakETag(value string) ETag {
+	return etag.NewWeakETag(value)
+}
@@ -10,9 +10,12 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
+	s4 "github.com/nguyengg/golambda/s3"
+	"io"
 	"io/ioutil"
 	"mime"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -56,8 +59,12 @@ func ProxyS3GET(ctx context.Context, client *s3.Client, bucket, key string, opts
 // ProxyS3GETWithRequestHeaders is a variant of ProxyS3GET with request headers.
 //
 // Only these headers are proxied: If-Match, If-Modified-Since, If-None-Match, If-Unmodified-Since, and Range.
+//
+// A single range returns http.StatusPartialContent with "Content-Range". Because S3 only supports one range per
+// request, multiple ranges (e.g. "bytes=0-99,200-299") are fetched individually (see s4.GetObjectRanges) and returned
+// as a multipart/byteranges body.
 func ProxyS3GETWithRequestHeaders(ctx context.Context, client *s3.Client, bucket, key string, header http.Header, opts ...Opt) (events.APIGatewayV2HTTPResponse, error) {
-	res, err := doGET(ctx, client, &s3.GetObjectInput{
+	return proxyS3GET(ctx, client, &s3.GetObjectInput{
 		Bucket:            aws.String(bucket),
 		Key:               aws.String(key),
 		IfMatch:           getIfMatch(header),
@@ -65,7 +72,70 @@ func ProxyS3GETWithRequestHeaders(ctx context.Context, client *s3.Client, bucket
 		IfNoneMatch:       getIfNoneMatch(header),
 		IfUnmodifiedSince: getIfUnmodifiedSince(header),
 		Range:             getRange(header),
-	})
+	}, opts...)
+}
+
+// ProxyS3URI is a variant of ProxyS3WithRequestHeaders that identifies the object with a s4.URIWithOwner so that
+// S3 rejects the request if the bucket is owned by a different account.
+func ProxyS3URI(ctx context.Context, client *s3.Client, method string, uri s4.URIWithOwner, header http.Header, opts ...Opt) (events.APIGatewayV2HTTPResponse, error) {
+	switch method {
+	case http.MethodGet:
+		return proxyS3GET(ctx, client, s4.AddToGetObject(uri.Get(nil), header), opts...)
+	case http.MethodHead:
+		res, err := doHEAD(ctx, client, s4.AddToHeadObject(uri.Head(nil), header))
+		if err != nil {
+			return res, err
+		}
+
+		for _, opt := range opts {
+			opt(&res)
+		}
+
+		return res, nil
+	default:
+		return ProxyS3WithRequestHeaders(ctx, client, method, uri.Bucket, uri.Key, header, opts...)
+	}
+}
+
+// RedirectS3 answers with a redirect to a presigned GetObject URL of the object that expires after the given duration.
+//
+// Use this to serve objects larger than the API Gateway payload limit: the client downloads the object directly from
+// S3, and because the client sends its own "Range" and conditional headers to S3, those work as well. The statusCode
+// should be http.StatusFound or http.StatusTemporaryRedirect. The URL honours [s4.URIWithOwner.ExpectedBucketOwner]
+// (see s4.URIWithOwner.PresignGet).
+//
+// Usage:
+//
+//	presignClient := s3.NewPresignClient(client)
+//	return apigatewayhttpapi.RedirectS3(ctx, presignClient, uri, http.StatusTemporaryRedirect, 5*time.Minute)
+func RedirectS3(ctx context.Context, client s4.PresignGetObjectAPIClient, uri s4.URIWithOwner, statusCode int, expires time.Duration, opts ...Opt) (events.APIGatewayV2HTTPResponse, error) {
+	location, err := uri.PresignGet(ctx, client, nil, expires)
+	if err != nil {
+		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusInternalServerError}, fmt.Errorf("presign S3 GetObject: %v", err)
+	}
+
+	res := events.APIGatewayV2HTTPResponse{
+		StatusCode: statusCode,
+		Headers:    map[string]string{"Location": location},
+	}
+
+	for _, opt := range opts {
+		opt(&res)
+	}
+
+	return res, nil
+}
+
+func proxyS3GET(ctx context.Context, client *s3.Client, input *s3.GetObjectInput, opts ...Opt) (events.APIGatewayV2HTTPResponse, error) {
+	var (
+		res events.APIGatewayV2HTTPResponse
+		err error
+	)
+	if input.Range != nil && strings.Contains(*input.Range, ",") {
+		res, err = doGETRanges(ctx, client, input)
+	} else {
+		res, err = doGET(ctx, client, input)
+	}
 	if err != nil {
 		return res, err
 	}
@@ -117,10 +187,15 @@ func doGET(ctx context.Context, client *s3.Client, input *s3.GetObjectInput) (ev
 		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusInternalServerError}, fmt.Errorf("read S3 content: %v", err)
 	}
 
+	statusCode := http.StatusOK
+	if output.ContentRange != nil {
+		statusCode = http.StatusPartialContent
+	}
+
 	if output.ContentType != nil {
 		if t, _, err := mime.ParseMediaType(*output.ContentType); err == nil && strings.HasPrefix(t, "text") {
 			return events.APIGatewayV2HTTPResponse{
-				StatusCode: http.StatusOK,
+				StatusCode: statusCode,
 				Body:       string(data),
 				Headers:    headersForGetObjectOutput(output),
 			}, nil
@@ -128,13 +203,79 @@ func doGET(ctx context.Context, client *s3.Client, input *s3.GetObjectInput) (ev
 	}
 
 	return events.APIGatewayV2HTTPResponse{
-		StatusCode:      http.StatusOK,
+		StatusCode:      statusCode,
 		Body:            base64.StdEncoding.EncodeToString(data),
 		Headers:         headersForGetObjectOutput(output),
 		IsBase64Encoded: true,
 	}, nil
 }
 
+// doGETRanges uses HeadObject to evaluate the conditional headers and find the size of the object, then GetObject for
+// every range.
+func doGETRanges(ctx context.Context, client *s3.Client, input *s3.GetObjectInput) (events.APIGatewayV2HTTPResponse, error) {
+	head, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:              input.Bucket,
+		Key:                 input.Key,
+		ExpectedBucketOwner: input.ExpectedBucketOwner,
+		VersionId:           input.VersionId,
+		IfMatch:             input.IfMatch,
+		IfModifiedSince:     input.IfModifiedSince,
+		IfNoneMatch:         input.IfNoneMatch,
+		IfUnmodifiedSince:   input.IfUnmodifiedSince,
+	})
+	if err != nil {
+		return convertS3Error(err), nil
+	}
+
+	size := aws.ToInt64(head.ContentLength)
+	ranges, err := s4.ParseRange(aws.ToString(input.Range), size)
+	if err != nil {
+		return events.APIGatewayV2HTTPResponse{
+			StatusCode: http.StatusRequestedRangeNotSatisfiable,
+			Headers:    map[string]string{"Content-Range": "bytes */" + strconv.FormatInt(size, 10)},
+		}, nil
+	}
+
+	// the preconditions have passed so every part only needs to match the same version of the object.
+	in := *input
+	in.IfMatch = head.ETag
+	in.IfModifiedSince = nil
+	in.IfNoneMatch = nil
+	in.IfUnmodifiedSince = nil
+
+	switch len(ranges) {
+	case 0:
+		in.Range = nil
+		return doGET(ctx, client, &in)
+	case 1:
+		in.Range = aws.String(ranges[0].String())
+		return doGET(ctx, client, &in)
+	}
+
+	body, contentType, _ := s4.GetObjectRanges(ctx, client, &in, ranges, size, aws.ToString(head.ContentType))
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusInternalServerError}, fmt.Errorf("read S3 content: %v", err)
+	}
+
+	headers := map[string]string{"Content-Type": contentType}
+	if head.ETag != nil {
+		headers["ETag"] = *head.ETag
+	}
+	if head.LastModified != nil {
+		headers["Last-Modified"] = head.LastModified.Format(http.TimeFormat)
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode:      http.StatusPartialContent,
+		Body:            base64.StdEncoding.EncodeToString(data),
+		Headers:         headers,
+		IsBase64Encoded: true,
+	}, nil
+}
+
 func doHEAD(ctx context.Context, client *s3.Client, input *s3.HeadObjectInput) (events.APIGatewayV2HTTPResponse, error) {
 	output, err := client.HeadObject(ctx, input)
 	if err != nil {
@@ -229,9 +370,14 @@ func headersForHeadObjectOutput(output *s3.HeadObjectOutput) map[string]string {
 }
 
 func headersForGetObjectOutput(output *s3.GetObjectOutput) map[string]string {
-	return map[string]string{
+	headers := map[string]string{
 		"Content-Type":  aws.ToString(output.ContentType),
 		"ETag":          aws.ToString(output.ETag),
 		"Last-Modified": output.LastModified.Format(http.TimeFormat),
 	}
+	if output.ContentRange != nil {
+		headers["Content-Range"] = *output.ContentRange
+	}
+
+	return headers
 }
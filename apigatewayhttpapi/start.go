@@ -7,9 +7,11 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/nguyengg/golambda/configsupport"
 	"github.com/nguyengg/golambda/logsupport"
-	"github.com/nguyengg/golambda/metrics"
 	"github.com/nguyengg/golambda/start"
+	"github.com/nguyengg/golambda/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"log"
+	"net/http"
 )
 
 // Handler for API Gateway HTTP API requests using V2 payload request and response format.
@@ -18,12 +20,22 @@ type Handler func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (
 // Start starts the Lambda runtime loop with the specified Handler.
 func Start(handler Handler, options ...start.Option) {
 	opts := start.New(options)
+	lambda.StartWithOptions(wrap(handler, opts), opts.HandlerOptions...)
+}
+
+// Wrap returns the Handler that Start passes to the Lambda runtime, which is the given handler wrapped with metrics,
+// logging, and middlewares according to the options.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. with a local server or in tests.
+func Wrap(handler Handler, options ...start.Option) Handler {
+	return wrap(handler, start.New(options))
+}
 
-	lambda.StartHandlerFunc(func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (response events.APIGatewayV2HTTPResponse, err error) {
-		m := metrics.NewSimpleMetricsContext(
-			opts.LoggerProvider(ctx).WithContext(ctx),
-			request.RequestContext.RequestID,
-			request.RequestContext.TimeEpoch)
+func wrap(handler Handler, opts *start.Options) Handler {
+	h := start.Chain(opts, start.Handler[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse](handler))
+
+	return func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (response events.APIGatewayV2HTTPResponse, err error) {
+		m := opts.NewMetrics(ctx, request.RequestContext.RequestID, request.RequestContext.TimeEpoch)
 		ctx = m.WithContext(ctx)
 
 		if !opts.DisableSetUpGlobalLogger {
@@ -52,6 +64,16 @@ func Start(handler Handler, options ...start.Option) {
 
 		panicked := true
 
+		ctx, span := opts.StartSpan(ctx, "apigatewayhttpapi",
+			attribute.String("path", request.RequestContext.HTTP.Path),
+			attribute.String("method", request.RequestContext.HTTP.Method),
+			attribute.String("stage", request.RequestContext.Stage),
+			attribute.String("routeKey", request.RequestContext.RouteKey))
+		defer func() {
+			tracing.SetStatusCode(span, response.StatusCode)
+			tracing.End(span, err, panicked)
+		}()
+
 		if !opts.DisableMetricsLogging {
 			m.
 				SetProperty("path", request.RequestContext.HTTP.Path).
@@ -77,8 +99,17 @@ func Start(handler Handler, options ...start.Option) {
 			}()
 		}
 
-		response, err = handler(ctx, request)
+		if opts.RecoverPanics {
+			defer func() {
+				if r := recover(); r != nil {
+					_ = opts.Recover(ctx, r)
+					response, err = JSONError(http.StatusInternalServerError), nil
+				}
+			}()
+		}
+
+		response, err = h(ctx, request)
 		panicked = false
 		return
-	}, opts.HandlerOptions...)
+	}
 }
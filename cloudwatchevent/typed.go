@@ -0,0 +1,47 @@
+package cloudwatchevent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/nguyengg/golambda/metrics"
+	"github.com/nguyengg/golambda/start"
+)
+
+// CounterKeyDecodeFailure is the counter that is incremented if the event's detail cannot be decoded.
+const CounterKeyDecodeFailure = "decodeFailureCount"
+
+// TypedHandler is the handler for CloudWatch events whose detail is decoded as JSON into T.
+type TypedHandler[T any] func(ctx context.Context, request events.CloudWatchEvent, detail T) error
+
+// StartTyped is a variant of Start that decodes the event's detail into T.
+//
+// See NewTypedHandler for how the detail is decoded.
+func StartTyped[T any](handler TypedHandler[T], options ...start.Option) {
+	lambda.Start(WrapTyped(handler, options...))
+}
+
+// WrapTyped returns the handler that StartTyped passes to the Lambda runtime.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. in tests.
+func WrapTyped[T any](handler TypedHandler[T], options ...start.Option) Handler {
+	return Wrap(NewTypedHandler(handler), options...)
+}
+
+// NewTypedHandler converts a TypedHandler into a Handler that decodes the event's detail into T.
+//
+// If the detail cannot be decoded, the handler is not invoked, the CounterKeyDecodeFailure counter is incremented, and
+// the decode error is returned.
+func NewTypedHandler[T any](handler TypedHandler[T]) Handler {
+	return func(ctx context.Context, request events.CloudWatchEvent) error {
+		var detail T
+		if err := json.Unmarshal(request.Detail, &detail); err != nil {
+			metrics.Ctx(ctx).IncrementCount(CounterKeyDecodeFailure)
+			return fmt.Errorf("decode detail of event %s: %w", request.ID, err)
+		}
+
+		return handler(ctx, request, detail)
+	}
+}
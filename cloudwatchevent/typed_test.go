@@ -0,0 +1,69 @@
+package cloudwatchevent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/golambdatest"
+	"testing"
+)
+
+func TestWrapTyped(t *testing.T) {
+	type Detail struct {
+		Name string `json:"name"`
+	}
+
+	errHandler := errors.New("handler error")
+
+	tests := []struct {
+		name                 string
+		detail               string
+		wantName             string
+		wantErr              error
+		wantDecodeFailure    int64
+		wantHandlerNotCalled bool
+	}{
+		{name: "decoded", detail: `{"name":"hello"}`, wantName: "hello"},
+		{name: "malformed detail", detail: `not json`, wantDecodeFailure: 1, wantHandlerNotCalled: true},
+		{name: "handler error", detail: `{"name":"fail"}`, wantName: "fail", wantErr: errHandler},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				called bool
+				got    string
+			)
+			r := golambdatest.NewRecorder()
+			h := WrapTyped(func(ctx context.Context, request events.CloudWatchEvent, detail Detail) error {
+				called, got = true, detail.Name
+				if detail.Name == "fail" {
+					return errHandler
+				}
+				return nil
+			}, r.Options()...)
+
+			err := h(golambdatest.NewContext(context.Background()), events.CloudWatchEvent{
+				ID:     "1",
+				Detail: json.RawMessage(tt.detail),
+			})
+			switch {
+			case tt.wantHandlerNotCalled:
+				if err == nil {
+					t.Errorf("WrapTyped() error = nil, want decode error")
+				}
+				if called {
+					t.Errorf("WrapTyped() invoked handler with malformed detail")
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Errorf("WrapTyped() error = %v, want %v", err, tt.wantErr)
+			case got != tt.wantName:
+				t.Errorf("WrapTyped() got = %q, want %q", got, tt.wantName)
+			}
+
+			if got, _ := r.LastMetrics(t).Counter(CounterKeyDecodeFailure); got != tt.wantDecodeFailure {
+				t.Errorf("counter %s = %d, want %d", CounterKeyDecodeFailure, got, tt.wantDecodeFailure)
+			}
+		})
+	}
+}
@@ -0,0 +1,430 @@
+// Package ddb creates GetItem, PutItem, UpdateItem, and DeleteItem requests from structs whose fields are annotated with
+// additional options in the `dynamodbav` struct tag.
+//
+// Usage:
+//
+//	type Item struct {
+//		Id           string                     `dynamodbav:"id,hashkey"`
+//		Sort         string                     `dynamodbav:"sort,sortkey"`
+//		Version      int64                      `dynamodbav:"version,version"`
+//		CreatedTime  timestamp.EpochMillisecond `dynamodbav:"createdTime,createdTime"`
+//		ModifiedTime timestamp.EpochMillisecond `dynamodbav:"modifiedTime,modifiedTime"`
+//	}
+//
+//	table := must.Must(ddb.NewTable[Item]("my-table"))
+//	item := &Item{Id: "hello", Sort: "world"}
+//	input, next, err := table.Put(item)
+//	if err == nil {
+//		if _, err = client.PutItem(ctx, input); err == nil {
+//			*item = *next
+//		}
+//	}
+//
+// The options are ignored by attributevalue so the same struct can be used with attributevalue.MarshalMap and
+// attributevalue.UnmarshalMap.
+package ddb
+
+import (
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nguyengg/golambda/ddb/timestamp"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Options in the `dynamodbav` struct tag that are recognised by NewTable.
+const (
+	// TagHashKey marks the partition key. Exactly one field must have this option.
+	TagHashKey = "hashkey"
+	// TagSortKey marks the optional sort key.
+	TagSortKey = "sortkey"
+	// TagVersion marks the optional version attribute that is used for optimistic locking. The field must be an
+	// integer type.
+	TagVersion = "version"
+	// TagCreatedTime marks the optional attribute that is set to the current time when the item is first put. The
+	// field must be one of the timestamp types from package timestamp.
+	TagCreatedTime = "createdTime"
+	// TagModifiedTime marks the optional attribute that is set to the current time whenever the item is put or
+	// updated. The field must be one of the timestamp types from package timestamp.
+	TagModifiedTime = "modifiedTime"
+)
+
+// Table creates requests for items of type T.
+//
+// The methods that create PutItem and UpdateItem requests never modify the given item. Instead, they also return a copy
+// of the item with the version and timestamp attributes that the request writes; the caller should apply the copy only
+// after the request succeeds.
+type Table[T any] struct {
+	// TableName is the name of the DynamoDB table.
+	TableName string
+
+	hashKey      *attribute
+	sortKey      *attribute
+	version      *attribute
+	createdTime  *attribute
+	modifiedTime *attribute
+}
+
+// attribute is a struct field that is mapped to a DynamoDB attribute.
+type attribute struct {
+	name  string
+	index []int
+}
+
+// timestampType is implemented by every timestamp type from package timestamp.
+type timestampType interface {
+	ToAttributeValueMap(key string) map[string]types.AttributeValue
+}
+
+// ErrZeroVersion is returned by Table.Update if the item's version is zero.
+//
+// The version of an item that exists in DynamoDB is never zero, so the item must be read first, or written with
+// Table.Put instead.
+var ErrZeroVersion = errors.New("cannot update item with zero version")
+
+// now is replaced in tests.
+var now = time.Now
+
+// NewTable parses the struct tags of T to create a new Table.
+//
+// Returns an error if T is not a struct, if T does not have exactly one hash key, or if the version or timestamp
+// attributes have unsupported types.
+func NewTable[T any](tableName string) (*Table[T], error) {
+	rt := reflect.TypeFor[T]()
+	if rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("type %v is not a struct", rt)
+	}
+
+	t := &Table[T]{TableName: tableName}
+	for _, f := range reflect.VisibleFields(rt) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+
+		tag := f.Tag.Get("dynamodbav")
+		if tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		a := &attribute{name: parts[0], index: f.Index}
+		if a.name == "" {
+			a.name = f.Name
+		}
+
+		for _, opt := range parts[1:] {
+			var dest **attribute
+			switch opt {
+			case TagHashKey:
+				dest = &t.hashKey
+			case TagSortKey:
+				dest = &t.sortKey
+			case TagVersion:
+				switch f.Type.Kind() {
+				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+					reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				default:
+					return nil, fmt.Errorf("version attribute %s must be an integer type, got %v", a.name, f.Type)
+				}
+				dest = &t.version
+			case TagCreatedTime, TagModifiedTime:
+				if _, err := newTimestamp(f.Type, time.Time{}); err != nil {
+					return nil, fmt.Errorf("%s attribute %s: %w", opt, a.name, err)
+				}
+				if opt == TagCreatedTime {
+					dest = &t.createdTime
+				} else {
+					dest = &t.modifiedTime
+				}
+			default:
+				continue
+			}
+
+			if *dest != nil {
+				return nil, fmt.Errorf("type %v has more than one %s attribute: %s and %s", rt, opt, (*dest).name, a.name)
+			}
+			*dest = a
+		}
+	}
+
+	if t.hashKey == nil {
+		return nil, fmt.Errorf("type %v has no %s attribute", rt, TagHashKey)
+	}
+
+	return t, nil
+}
+
+// Key returns the primary key of the item.
+func (t *Table[T]) Key(item *T) (map[string]types.AttributeValue, error) {
+	v := reflect.ValueOf(item).Elem()
+
+	key := make(map[string]types.AttributeValue, 2)
+	for _, a := range []*attribute{t.hashKey, t.sortKey} {
+		if a == nil {
+			continue
+		}
+
+		av, err := attributevalue.Marshal(v.FieldByIndex(a.index).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("marshal key attribute %s: %w", a.name, err)
+		}
+		key[a.name] = av
+	}
+
+	return key, nil
+}
+
+// Get creates the GetItem request for the item with the same primary key as the given item.
+func (t *Table[T]) Get(item *T) (*dynamodb.GetItemInput, error) {
+	key, err := t.Key(item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.GetItemInput{TableName: aws.String(t.TableName), Key: key}, nil
+}
+
+// Put creates the PutItem request for the item.
+//
+// If the item has a version attribute, the request succeeds only if the item does not exist (if the current version
+// is zero) or if the item in DynamoDB has the same version, and the version is incremented in the request. The
+// modified time is set to now, and so is the created time if the item is new (zero version or zero created time).
+//
+// The returned next item is a copy of the given item with the new version and timestamps; the given item is left
+// unchanged. Replace the item with next once the PutItem request succeeds.
+//
+// Additional conditions are AND-ed together with the version condition.
+func (t *Table[T]) Put(item *T, conditions ...expression.ConditionBuilder) (input *dynamodb.PutItemInput, next *T, err error) {
+	cp := *item
+	v := reflect.ValueOf(&cp).Elem()
+	ts := now()
+
+	if t.createdTime != nil && (isNew(v, t.createdTime) || t.version != nil && isNew(v, t.version)) {
+		if err = setTimestamp(v, t.createdTime, ts); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var condition *expression.ConditionBuilder
+	if t.version != nil {
+		c := t.versionCondition(v)
+		condition = &c
+		incrementVersion(v, t.version)
+	}
+	if t.modifiedTime != nil {
+		if err = setTimestamp(v, t.modifiedTime, ts); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	av, err := attributevalue.MarshalMap(&cp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal item: %w", err)
+	}
+
+	input = &dynamodb.PutItemInput{TableName: aws.String(t.TableName), Item: av}
+
+	expr, ok, err := buildCondition(condition, conditions)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok {
+		input.ConditionExpression = expr.Condition()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+	}
+
+	return input, &cp, nil
+}
+
+// Update creates the UpdateItem request for the item with the given update expression.
+//
+// If the item has a version attribute, the request succeeds only if the item in DynamoDB has the same version, and the
+// version is incremented in the request. Returns ErrZeroVersion if the item's version is zero since the version of the
+// item in DynamoDB is unknown. The modified time is set to now in the request.
+//
+// The returned next item is a copy of the given item with the new version and modified time; the given item is left
+// unchanged. Only those attributes are updated in next, so use ReturnValues to read back the attributes changed by
+// the update expression. Replace the item with next once the UpdateItem request succeeds.
+//
+// Additional conditions are AND-ed together with the version condition.
+func (t *Table[T]) Update(item *T, update expression.UpdateBuilder, conditions ...expression.ConditionBuilder) (input *dynamodb.UpdateItemInput, next *T, err error) {
+	cp := *item
+	v := reflect.ValueOf(&cp).Elem()
+
+	key, err := t.Key(item)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var condition *expression.ConditionBuilder
+	if t.version != nil {
+		if isNew(v, t.version) {
+			return nil, nil, ErrZeroVersion
+		}
+
+		c := t.versionCondition(v)
+		condition = &c
+
+		update = update.Add(expression.Name(t.version.name), expression.Value(1))
+		incrementVersion(v, t.version)
+	}
+	if t.modifiedTime != nil {
+		if err = setTimestamp(v, t.modifiedTime, now()); err != nil {
+			return nil, nil, err
+		}
+
+		m := v.FieldByIndex(t.modifiedTime.index).Interface().(timestampType).ToAttributeValueMap(t.modifiedTime.name)
+		update = update.Set(expression.Name(t.modifiedTime.name), expression.Value(rawAttributeValue{m[t.modifiedTime.name]}))
+	}
+
+	builder := expression.NewBuilder().WithUpdate(update)
+	if c, ok := and(condition, conditions); ok {
+		builder = builder.WithCondition(c)
+	}
+
+	expr, err := builder.Build()
+	if err != nil {
+		return nil, nil, fmt.Errorf("build expression: %w", err)
+	}
+
+	return &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(t.TableName),
+		Key:                       key,
+		ConditionExpression:       expr.Condition(),
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}, &cp, nil
+}
+
+// Delete creates the DeleteItem request for the item.
+//
+// If the item has a non-zero version, the request succeeds only if the item in DynamoDB has the same version.
+//
+// Additional conditions are AND-ed together with the version condition.
+func (t *Table[T]) Delete(item *T, conditions ...expression.ConditionBuilder) (*dynamodb.DeleteItemInput, error) {
+	v := reflect.ValueOf(item).Elem()
+
+	key, err := t.Key(item)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.DeleteItemInput{TableName: aws.String(t.TableName), Key: key}
+
+	var condition *expression.ConditionBuilder
+	if t.version != nil && !isNew(v, t.version) {
+		c := t.versionCondition(v)
+		condition = &c
+	}
+
+	expr, ok, err := buildCondition(condition, conditions)
+	if err != nil || !ok {
+		return input, err
+	}
+
+	input.ConditionExpression = expr.Condition()
+	input.ExpressionAttributeNames = expr.Names()
+	input.ExpressionAttributeValues = expr.Values()
+	return input, nil
+}
+
+// versionCondition returns the condition that the item does not exist if its version is zero, or that the item in
+// DynamoDB has the same version otherwise.
+func (t *Table[T]) versionCondition(v reflect.Value) expression.ConditionBuilder {
+	if isNew(v, t.version) {
+		return expression.AttributeNotExists(expression.Name(t.hashKey.name))
+	}
+
+	return expression.Name(t.version.name).Equal(expression.Value(v.FieldByIndex(t.version.index).Interface()))
+}
+
+func isNew(v reflect.Value, a *attribute) bool {
+	return v.FieldByIndex(a.index).IsZero()
+}
+
+func incrementVersion(v reflect.Value, a *attribute) {
+	f := v.FieldByIndex(a.index)
+	if f.CanInt() {
+		f.SetInt(f.Int() + 1)
+	} else {
+		f.SetUint(f.Uint() + 1)
+	}
+}
+
+func setTimestamp(v reflect.Value, a *attribute, ts time.Time) error {
+	f := v.FieldByIndex(a.index)
+
+	nv, err := newTimestamp(f.Type(), ts)
+	if err != nil {
+		return fmt.Errorf("set timestamp attribute %s: %w", a.name, err)
+	}
+
+	f.Set(nv)
+	return nil
+}
+
+// newTimestamp creates a value of the given timestamp type.
+func newTimestamp(rt reflect.Type, ts time.Time) (reflect.Value, error) {
+	var v interface{}
+	switch rt {
+	case reflect.TypeFor[timestamp.Timestamp]():
+		v = timestamp.Timestamp(ts.UTC())
+	case reflect.TypeFor[timestamp.EpochMillisecond]():
+		v = timestamp.EpochMillisecond(ts)
+	case reflect.TypeFor[timestamp.EpochSecond]():
+		v = timestamp.EpochSecond(ts)
+	case reflect.TypeFor[timestamp.Day]():
+		v = timestamp.TruncateToStartOfDay(ts)
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported timestamp type %v", rt)
+	}
+
+	return reflect.ValueOf(v), nil
+}
+
+// and combines the optional condition with the additional conditions.
+func and(condition *expression.ConditionBuilder, conditions []expression.ConditionBuilder) (expression.ConditionBuilder, bool) {
+	if condition != nil {
+		conditions = append([]expression.ConditionBuilder{*condition}, conditions...)
+	}
+
+	switch len(conditions) {
+	case 0:
+		return expression.ConditionBuilder{}, false
+	case 1:
+		return conditions[0], true
+	default:
+		return expression.And(conditions[0], conditions[1], conditions[2:]...), true
+	}
+}
+
+func buildCondition(condition *expression.ConditionBuilder, conditions []expression.ConditionBuilder) (expression.Expression, bool, error) {
+	c, ok := and(condition, conditions)
+	if !ok {
+		return expression.Expression{}, false, nil
+	}
+
+	expr, err := expression.NewBuilder().WithCondition(c).Build()
+	if err != nil {
+		return expr, false, fmt.Errorf("build expression: %w", err)
+	}
+
+	return expr, true, nil
+}
+
+// rawAttributeValue allows a types.AttributeValue to be passed to expression.Value as-is.
+type rawAttributeValue struct {
+	av types.AttributeValue
+}
+
+func (r rawAttributeValue) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return r.av, nil
+}
@@ -0,0 +1,188 @@
+package ddb
+
+import (
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nguyengg/golambda/ddb/timestamp"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	Id           string                     `dynamodbav:"id,hashkey"`
+	Sort         int                        `dynamodbav:"sort,sortkey"`
+	Version      int64                      `dynamodbav:"version,version"`
+	CreatedTime  timestamp.Timestamp        `dynamodbav:"createdTime,createdTime"`
+	ModifiedTime timestamp.EpochMillisecond `dynamodbav:"modifiedTime,modifiedTime"`
+	Name         string                     `dynamodbav:"name"`
+}
+
+func setNow(t *testing.T, ts time.Time) {
+	now = func() time.Time { return ts }
+	t.Cleanup(func() { now = time.Now })
+}
+
+func TestNewTable_errors(t *testing.T) {
+	if _, err := NewTable[struct{ Id string }]("table"); err == nil {
+		t.Errorf("NewTable() without hash key should fail")
+	}
+	if _, err := NewTable[struct {
+		Id      string `dynamodbav:"id,hashkey"`
+		Version string `dynamodbav:"version,version"`
+	}]("table"); err == nil {
+		t.Errorf("NewTable() with string version should fail")
+	}
+	if _, err := NewTable[struct {
+		Id      string    `dynamodbav:"id,hashkey"`
+		Created time.Time `dynamodbav:"created,createdTime"`
+	}]("table"); err == nil {
+		t.Errorf("NewTable() with time.Time created time should fail")
+	}
+}
+
+func TestTable_Put(t *testing.T) {
+	ts := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	setNow(t, ts)
+
+	table, err := NewTable[testItem]("table")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item := &testItem{Id: "hello", Sort: 1, Name: "world"}
+	input, next, err := table.Put(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (testItem{Id: "hello", Sort: 1, Name: "world"}); *item != want {
+		t.Errorf("Put() modified item = %#v, want %#v", item, want)
+	}
+	if next.Version != 1 || !next.CreatedTime.Equal(timestamp.Timestamp(ts)) || !next.ModifiedTime.ToTime().Equal(ts) {
+		t.Errorf("Put() next = %#v", next)
+	}
+	if got := aws.ToString(input.ConditionExpression); got != "attribute_not_exists (#0)" {
+		t.Errorf("Put() condition = %s", got)
+	}
+	if want := map[string]types.AttributeValue{
+		"id":           &types.AttributeValueMemberS{Value: "hello"},
+		"sort":         &types.AttributeValueMemberN{Value: "1"},
+		"version":      &types.AttributeValueMemberN{Value: "1"},
+		"createdTime":  &types.AttributeValueMemberS{Value: "2006-01-02T15:04:05.000Z"},
+		"modifiedTime": &types.AttributeValueMemberN{Value: "1136214245000"},
+		"name":         &types.AttributeValueMemberS{Value: "world"},
+	}; !reflect.DeepEqual(input.Item, want) {
+		t.Errorf("Put() item = %#v, want %#v", input.Item, want)
+	}
+
+	// putting the same item again must condition on the previous version and keep the created time.
+	setNow(t, ts.Add(time.Hour))
+	item = next
+	input, next, err = table.Put(item, expression.Name("name").Equal(expression.Value("world")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if item.Version != 1 || !item.ModifiedTime.ToTime().Equal(ts) {
+		t.Errorf("Put() modified item = %#v", item)
+	}
+	if next.Version != 2 || !next.CreatedTime.Equal(timestamp.Timestamp(ts)) || !next.ModifiedTime.ToTime().Equal(ts.Add(time.Hour)) {
+		t.Errorf("Put() next = %#v", next)
+	}
+	if got := aws.ToString(input.ConditionExpression); got != "(#0 = :0) AND (#1 = :1)" {
+		t.Errorf("Put() condition = %s", got)
+	}
+	if want := map[string]types.AttributeValue{
+		":0": &types.AttributeValueMemberN{Value: "1"},
+		":1": &types.AttributeValueMemberS{Value: "world"},
+	}; !reflect.DeepEqual(input.ExpressionAttributeValues, want) {
+		t.Errorf("Put() values = %#v, want %#v", input.ExpressionAttributeValues, want)
+	}
+}
+
+func TestTable_Update(t *testing.T) {
+	ts := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	setNow(t, ts)
+
+	table, err := NewTable[testItem]("table")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item := &testItem{Id: "hello", Sort: 1, Version: 3}
+	input, next, err := table.Update(item, expression.Set(expression.Name("name"), expression.Value("world")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (testItem{Id: "hello", Sort: 1, Version: 3}); *item != want {
+		t.Errorf("Update() modified item = %#v, want %#v", item, want)
+	}
+	if next.Version != 4 || !next.ModifiedTime.ToTime().Equal(ts) {
+		t.Errorf("Update() next = %#v", next)
+	}
+	if want := map[string]types.AttributeValue{
+		"id":   &types.AttributeValueMemberS{Value: "hello"},
+		"sort": &types.AttributeValueMemberN{Value: "1"},
+	}; !reflect.DeepEqual(input.Key, want) {
+		t.Errorf("Update() key = %#v, want %#v", input.Key, want)
+	}
+	if got := aws.ToString(input.ConditionExpression); got != "#0 = :0" {
+		t.Errorf("Update() condition = %s", got)
+	}
+	if got := aws.ToString(input.UpdateExpression); got != "ADD #0 :1\nSET #1 = :2, #2 = :3\n" {
+		t.Errorf("Update() update = %q", got)
+	}
+	if want := map[string]string{"#0": "version", "#1": "name", "#2": "modifiedTime"}; !reflect.DeepEqual(input.ExpressionAttributeNames, want) {
+		t.Errorf("Update() names = %v, want %v", input.ExpressionAttributeNames, want)
+	}
+	if want := map[string]types.AttributeValue{
+		":0": &types.AttributeValueMemberN{Value: "3"},
+		":1": &types.AttributeValueMemberN{Value: "1"},
+		":2": &types.AttributeValueMemberS{Value: "world"},
+		":3": &types.AttributeValueMemberN{Value: "1136214245000"},
+	}; !reflect.DeepEqual(input.ExpressionAttributeValues, want) {
+		t.Errorf("Update() values = %#v, want %#v", input.ExpressionAttributeValues, want)
+	}
+}
+
+func TestTable_Update_zeroVersion(t *testing.T) {
+	table, err := NewTable[testItem]("table")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item := &testItem{Id: "hello", Sort: 1}
+	if _, _, err = table.Update(item, expression.Set(expression.Name("name"), expression.Value("world"))); !errors.Is(err, ErrZeroVersion) {
+		t.Errorf("Update() error = %v, want %v", err, ErrZeroVersion)
+	}
+	if want := (testItem{Id: "hello", Sort: 1}); *item != want {
+		t.Errorf("Update() modified item = %#v, want %#v", item, want)
+	}
+}
+
+func TestTable_Delete(t *testing.T) {
+	table, err := NewTable[testItem]("table")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input, err := table.Delete(&testItem{Id: "hello", Sort: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if input.ConditionExpression != nil {
+		t.Errorf("Delete() condition = %s, want none", aws.ToString(input.ConditionExpression))
+	}
+
+	input, err = table.Delete(&testItem{Id: "hello", Sort: 1, Version: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := aws.ToString(input.ConditionExpression); got != "#0 = :0" {
+		t.Errorf("Delete() condition = %s", got)
+	}
+}
@@ -0,0 +1,294 @@
+// Package set provides a generic Set that is marshalled as a DynamoDB string set (SS), number set (NS), or binary set
+// (BS) depending on its element type.
+package set
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// Element is the constraint for the element type of Set.
+//
+// Strings are marshalled as SS, []byte as BS, and every numeric type as NS.
+type Element interface {
+	~string | ~[]byte |
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Set is an unordered collection of unique elements.
+//
+// Unlike stringset.StringSet, Set does not need the `dynamodbav:",stringset"` struct tag because it implements
+// attributevalue.Marshaler and attributevalue.Unmarshaler. Because DynamoDB does not allow empty sets, an empty Set is
+// marshalled as NULL; add the `omitempty` tag option and enable attributevalue.EncoderOptions.OmitNullAttributeValues
+// to omit the attribute instead.
+//
+// DynamoDB numbers must be finite, so a Set of floats containing NaN or ±Inf fails to be marshalled. Because DynamoDB
+// treats -0 and 0 as the same number, so does Set.
+//
+// The zero value is an empty set ready for use. Add, Has, and Delete are O(1).
+type Set[T Element] struct {
+	m map[string]T
+}
+
+var _ attributevalue.Marshaler = Set[string]{}
+var _ attributevalue.Marshaler = &Set[string]{}
+var _ attributevalue.Unmarshaler = &Set[string]{}
+
+// New creates a new Set with duplicate values removed.
+func New[T Element](values ...T) Set[T] {
+	s := Set[T]{m: make(map[string]T, len(values))}
+	for _, v := range values {
+		s.m[key(v)] = v
+	}
+
+	return s
+}
+
+// Add returns true only if the value hasn't existed in the set before the invocation.
+func (s *Set[T]) Add(value T) bool {
+	k := key(value)
+	if _, ok := s.m[k]; ok {
+		return false
+	}
+
+	if s.m == nil {
+		s.m = make(map[string]T)
+	}
+	s.m[k] = value
+	return true
+}
+
+// Has returns true if the value exists in the set.
+func (s Set[T]) Has(value T) bool {
+	_, ok := s.m[key(value)]
+	return ok
+}
+
+// Delete returns true only if the value existed in the set before the invocation.
+func (s *Set[T]) Delete(value T) bool {
+	k := key(value)
+	if _, ok := s.m[k]; !ok {
+		return false
+	}
+
+	delete(s.m, k)
+	return true
+}
+
+// Len returns the number of elements in the set.
+func (s Set[T]) Len() int {
+	return len(s.m)
+}
+
+// Values returns the elements of the set in ascending order.
+func (s Set[T]) Values() []T {
+	values := make([]T, 0, len(s.m))
+	for _, v := range s.m {
+		values = append(values, v)
+	}
+
+	slices.SortFunc(values, compare[T])
+	return values
+}
+
+// Union returns a new set containing the elements that are in either this set or the other set.
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	res := Set[T]{m: make(map[string]T, len(s.m)+len(other.m))}
+	for k, v := range s.m {
+		res.m[k] = v
+	}
+	for k, v := range other.m {
+		res.m[k] = v
+	}
+
+	return res
+}
+
+// Intersect returns a new set containing the elements that are in both this set and the other set.
+func (s Set[T]) Intersect(other Set[T]) Set[T] {
+	res := Set[T]{m: make(map[string]T)}
+	for k, v := range s.m {
+		if _, ok := other.m[k]; ok {
+			res.m[k] = v
+		}
+	}
+
+	return res
+}
+
+// Difference returns a new set containing the elements that are in this set but not in the other set.
+func (s Set[T]) Difference(other Set[T]) Set[T] {
+	res := Set[T]{m: make(map[string]T)}
+	for k, v := range s.m {
+		if _, ok := other.m[k]; !ok {
+			res.m[k] = v
+		}
+	}
+
+	return res
+}
+
+// Equal returns true only if every element in this set is in the other set and vice versa.
+func (s Set[T]) Equal(other Set[T]) bool {
+	if len(s.m) != len(other.m) {
+		return false
+	}
+
+	for k := range s.m {
+		if _, ok := other.m[k]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MarshalDynamoDBAttributeValue must not use receiver pointer to allow both pointer and non-pointer usage.
+func (s Set[T]) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	if len(s.m) == 0 {
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	}
+
+	values := s.Values()
+
+	switch kindOf[T]() {
+	case reflect.String:
+		ss := make([]string, len(values))
+		for i, v := range values {
+			ss[i] = key(v)
+		}
+		return &types.AttributeValueMemberSS{Value: ss}, nil
+	case reflect.Slice:
+		bs := make([][]byte, len(values))
+		for i, v := range values {
+			bs[i] = bytes.Clone(reflect.ValueOf(v).Bytes())
+		}
+		return &types.AttributeValueMemberBS{Value: bs}, nil
+	default:
+		ns := make([]string, len(values))
+		for i, v := range values {
+			if rv := reflect.ValueOf(v); rv.CanFloat() && (math.IsNaN(rv.Float()) || math.IsInf(rv.Float(), 0)) {
+				return nil, fmt.Errorf("set marshal DDB AV error: %v is not a valid DynamoDB number", v)
+			}
+			ns[i] = key(v)
+		}
+		return &types.AttributeValueMemberNS{Value: ns}, nil
+	}
+}
+
+func (s *Set[T]) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	res := Set[T]{m: make(map[string]T)}
+
+	switch v := av.(type) {
+	case *types.AttributeValueMemberNULL:
+	case *types.AttributeValueMemberSS:
+		if kindOf[T]() != reflect.String {
+			return fmt.Errorf("set unmarshal DDB AV error: SS is not compatible with %v", reflect.TypeFor[T]())
+		}
+		for _, e := range v.Value {
+			var t T
+			reflect.ValueOf(&t).Elem().SetString(e)
+			res.m[key(t)] = t
+		}
+	case *types.AttributeValueMemberBS:
+		if kindOf[T]() != reflect.Slice {
+			return fmt.Errorf("set unmarshal DDB AV error: BS is not compatible with %v", reflect.TypeFor[T]())
+		}
+		for _, e := range v.Value {
+			var t T
+			reflect.ValueOf(&t).Elem().SetBytes(bytes.Clone(e))
+			res.m[key(t)] = t
+		}
+	case *types.AttributeValueMemberNS:
+		for _, e := range v.Value {
+			t, err := parseNumber[T](e)
+			if err != nil {
+				return fmt.Errorf("set unmarshal DDB AV error: %w", err)
+			}
+			res.m[key(t)] = t
+		}
+	default:
+		return fmt.Errorf("set unmarshal DDB AV error: unsupported type %T", av)
+	}
+
+	*s = res
+	return nil
+}
+
+func kindOf[T Element]() reflect.Kind {
+	return reflect.TypeFor[T]().Kind()
+}
+
+// key returns the string representation of the value, which is also its DynamoDB SS or NS representation.
+//
+// -0 has the same key as 0.
+func key[T Element](value T) string {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Slice:
+		return string(v.Bytes())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	default:
+		f := v.Float()
+		if f == 0 {
+			// strips the sign of -0.
+			f = 0
+		}
+		return strconv.FormatFloat(f, 'f', -1, v.Type().Bits())
+	}
+}
+
+func parseNumber[T Element](s string) (t T, err error) {
+	v := reflect.ValueOf(&t).Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, v.Type().Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, v.Type().Bits()); err == nil {
+			v.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	default:
+		err = fmt.Errorf("NS is not compatible with %v", v.Type())
+	}
+
+	return
+}
+
+func compare[T Element](a, b T) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.String:
+		return cmp.Compare(va.String(), vb.String())
+	case reflect.Slice:
+		return bytes.Compare(va.Bytes(), vb.Bytes())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(va.Int(), vb.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(va.Uint(), vb.Uint())
+	default:
+		return cmp.Compare(va.Float(), vb.Float())
+	}
+}
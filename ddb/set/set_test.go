@@ -0,0 +1,114 @@
+package set
+
+import (
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSet_MarshalDynamoDBAttributeValue(t *testing.T) {
+	type Item struct {
+		Strings Set[string]  `dynamodbav:"strings"`
+		Ints    Set[int]     `dynamodbav:"ints"`
+		Floats  Set[float64] `dynamodbav:"floats"`
+		Bytes   Set[[]byte]  `dynamodbav:"bytes"`
+		Empty   Set[string]  `dynamodbav:"empty"`
+		Omitted Set[string]  `dynamodbav:"omitted,omitempty"`
+	}
+
+	item := Item{
+		Strings: New("b", "a", "b"),
+		Ints:    New(10, 2, 10),
+		Floats:  New(1.5, 0.25),
+		Bytes:   New([]byte("b"), []byte("a")),
+	}
+
+	got, err := attributevalue.MarshalMapWithOptions(item, func(options *attributevalue.EncoderOptions) {
+		options.OmitNullAttributeValues = true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]types.AttributeValue{
+		"strings": &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		"ints":    &types.AttributeValueMemberNS{Value: []string{"2", "10"}},
+		"floats":  &types.AttributeValueMemberNS{Value: []string{"0.25", "1.5"}},
+		"bytes":   &types.AttributeValueMemberBS{Value: [][]byte{[]byte("a"), []byte("b")}},
+		"empty":   &types.AttributeValueMemberNULL{Value: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MarshalMap() got = %#v, want %#v", got, want)
+	}
+
+	var decoded Item
+	if err = attributevalue.UnmarshalMap(got, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Strings.Equal(item.Strings) || !decoded.Ints.Equal(item.Ints) || !decoded.Floats.Equal(item.Floats) || !decoded.Bytes.Equal(item.Bytes) || decoded.Empty.Len() != 0 {
+		t.Errorf("UnmarshalMap() got = %#v, want %#v", decoded, item)
+	}
+
+	if err = attributevalue.Unmarshal(&types.AttributeValueMemberSS{Value: []string{"a"}}, &decoded.Ints); err == nil {
+		t.Errorf("Unmarshal() SS into Set[int] should fail")
+	}
+}
+
+func TestSet_operations(t *testing.T) {
+	var s Set[int]
+	if !s.Add(1) || s.Add(1) || !s.Add(2) || !s.Add(3) {
+		t.Errorf("Add() returned unexpected values")
+	}
+	if !s.Has(2) || s.Has(4) {
+		t.Errorf("Has() returned unexpected values")
+	}
+	if !s.Delete(3) || s.Delete(3) {
+		t.Errorf("Delete() returned unexpected values")
+	}
+
+	other := New(2, 5)
+	if got := s.Union(other).Values(); !reflect.DeepEqual(got, []int{1, 2, 5}) {
+		t.Errorf("Union() got = %v", got)
+	}
+	if got := s.Intersect(other).Values(); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("Intersect() got = %v", got)
+	}
+	if got := s.Difference(other).Values(); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("Difference() got = %v", got)
+	}
+	if s.Len() != 2 {
+		t.Errorf("Len() got = %d, want 2", s.Len())
+	}
+}
+
+func TestSet_negativeZero(t *testing.T) {
+	s := New(0.0, math.Copysign(0, -1))
+	if s.Len() != 1 {
+		t.Errorf("New(0, -0).Len() got = %d, want 1", s.Len())
+	}
+	if s.Add(math.Copysign(0, -1)) {
+		t.Errorf("Add(-0) returned true for set containing 0")
+	}
+
+	av, err := s.MarshalDynamoDBAttributeValue()
+	if err != nil {
+		t.Fatalf("MarshalDynamoDBAttributeValue() error = %v", err)
+	}
+	if want := (&types.AttributeValueMemberNS{Value: []string{"0"}}); !reflect.DeepEqual(av, want) {
+		t.Errorf("MarshalDynamoDBAttributeValue() got = %#v, want %#v", av, want)
+	}
+}
+
+func TestSet_MarshalDynamoDBAttributeValue_nonFinite(t *testing.T) {
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := New(1.5, v).MarshalDynamoDBAttributeValue(); err == nil {
+			t.Errorf("MarshalDynamoDBAttributeValue() with %v should fail", v)
+		}
+	}
+
+	if _, err := New(float32(math.Inf(1))).MarshalDynamoDBAttributeValue(); err == nil {
+		t.Errorf("MarshalDynamoDBAttributeValue() with float32 +Inf should fail")
+	}
+}
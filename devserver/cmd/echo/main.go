@@ -0,0 +1,35 @@
+// Command echo serves a Lambda Function URL handler locally that responds with the JSON-encoded request it receives.
+//
+// Use it to inspect how devserver translates HTTP requests into Lambda events:
+//
+//	go run github.com/nguyengg/golambda/devserver/cmd/echo -addr localhost:8080
+//	curl -b "session=abc" "http://localhost:8080/hello?name=world"
+package main
+
+import (
+	"flag"
+	"github.com/nguyengg/golambda/devserver"
+	"github.com/nguyengg/golambda/lambdafunctionurl"
+	"log"
+	"net/http"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	stream := flag.Bool("streaming", false, "serve in RESPONSE_STREAM instead of BUFFERED invoke mode")
+	flag.Parse()
+
+	echo := func(c lambdafunctionurl.Context) error {
+		return c.RespondOKWithJSON(c.Request())
+	}
+
+	var handler http.Handler
+	if *stream {
+		handler = devserver.FunctionURLStreaming(lambdafunctionurl.NewStreamingHandler(echo))
+	} else {
+		handler = devserver.FunctionURL(lambdafunctionurl.NewHandler(echo))
+	}
+
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, handler))
+}
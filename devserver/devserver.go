@@ -0,0 +1,230 @@
+// Package devserver serves Lambda Function URL and API Gateway HTTP API handlers on a local net/http server.
+//
+// Real HTTP requests are translated into events.LambdaFunctionURLRequest or events.APIGatewayV2HTTPRequest, and the
+// handler's responses are translated back. The handlers are wrapped the same way their Start functions would so the
+// metrics and logging behaviour is identical to running in Lambda.
+//
+// Usage:
+//
+//	http.ListenAndServe("localhost:8080", devserver.FunctionURL(lambdafunctionurl.NewHandler(func(c lambdafunctionurl.Context) error {
+//		return c.RespondOKWithText("hello, world!")
+//	})))
+package devserver
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/nguyengg/golambda/apigatewayhttpapi"
+	"github.com/nguyengg/golambda/apigatewayhttpapi/framework"
+	"github.com/nguyengg/golambda/lambdafunctionurl"
+	"github.com/nguyengg/golambda/start"
+	"io"
+	"log"
+	"net/http"
+)
+
+// InvokedFunctionArn is the [lambdacontext.LambdaContext.InvokedFunctionArn] of every local invocation.
+const InvokedFunctionArn = "arn:aws:lambda:local:000000000000:function:devserver"
+
+// FunctionURL returns an http.Handler that serves the lambdafunctionurl.Handler in BUFFERED invoke mode.
+//
+// The handler is wrapped with lambdafunctionurl.Wrap using the given options.
+func FunctionURL(handler lambdafunctionurl.Handler, options ...start.Option) http.Handler {
+	h := lambdafunctionurl.Wrap(handler, options...)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := parse(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := h(newContext(r.Context(), p), newFunctionURLRequest(p))
+		if err != nil {
+			log.Printf("ERROR handler failed with error: %v", err)
+			http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+			return
+		}
+
+		writeResponse(w, res.StatusCode, res.Headers, nil, res.Cookies, res.Body, res.IsBase64Encoded)
+	})
+}
+
+// FunctionURLStreaming returns an http.Handler that serves the lambdafunctionurl.StreamingHandler in RESPONSE_STREAM
+// invoke mode.
+//
+// The handler is wrapped with lambdafunctionurl.WrapStreaming using the given options. The response body is flushed to
+// the client after every write.
+func FunctionURLStreaming(handler lambdafunctionurl.StreamingHandler, options ...start.Option) http.Handler {
+	h := lambdafunctionurl.WrapStreaming(handler, options...)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := parse(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := h(newContext(r.Context(), p), newFunctionURLRequest(p))
+		if err != nil {
+			log.Printf("ERROR handler failed with error: %v", err)
+			http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+			return
+		}
+		if res == nil {
+			writeHeader(w, http.StatusOK, nil, nil, nil)
+			return
+		}
+
+		defer func() {
+			if err := res.Close(); err != nil {
+				log.Printf("ERROR close response body: %v", err)
+			}
+		}()
+
+		writeHeader(w, res.StatusCode, res.Headers, nil, res.Cookies)
+		if res.Body == nil {
+			return
+		}
+
+		if _, err = io.Copy(&flushWriter{w: w, rc: http.NewResponseController(w)}, res.Body); err != nil {
+			log.Printf("ERROR stream response body: %v", err)
+		}
+	})
+}
+
+// HTTPAPI returns an http.Handler that serves the apigatewayhttpapi.Handler.
+//
+// The routes argument is the route table of the API, containing route keys such as "GET /users/{id}" or
+// "ANY /files/{proxy+}" which are used to populate the request's RouteKey and PathParameters. Requests that don't match
+// any route receive a 404 response, unless "$default" is one of the route keys. If no route keys are given, every
+// request matches "$default".
+//
+// The handler is wrapped with apigatewayhttpapi.Wrap using the given options. HTTPAPI panics if a route key is invalid.
+func HTTPAPI(handler apigatewayhttpapi.Handler, routes []string, options ...start.Option) http.Handler {
+	rs, err := parseRoutes(routes)
+	if err != nil {
+		panic(err)
+	}
+
+	h := apigatewayhttpapi.Wrap(handler, options...)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routeKey, pathParameters, ok := rs.match(r.Method, r.URL.Path)
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+
+		p, err := parse(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		req := newHTTPAPIRequest(p)
+		req.RouteKey = routeKey
+		req.RequestContext.RouteKey = routeKey
+		req.PathParameters = pathParameters
+
+		res, err := h(newContext(r.Context(), p), req)
+		if err != nil {
+			log.Printf("ERROR handler failed with error: %v", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"message":"Internal Server Error"}`))
+			return
+		}
+
+		writeResponse(w, res.StatusCode, res.Headers, res.MultiValueHeaders, res.Cookies, res.Body, res.IsBase64Encoded)
+	})
+}
+
+// Framework is a variant of HTTPAPI for handlers that would be started with framework.Start.
+func Framework(handler func(*framework.Context) error, routes []string, options ...start.Option) http.Handler {
+	return HTTPAPI(framework.NewHandler(handler), routes, options...)
+}
+
+// newContext attaches a lambdacontext.LambdaContext to the context.
+func newContext(ctx context.Context, p parts) context.Context {
+	return lambdacontext.NewContext(ctx, &lambdacontext.LambdaContext{
+		AwsRequestID:       p.requestID,
+		InvokedFunctionArn: InvokedFunctionArn,
+	})
+}
+
+func newFunctionURLRequest(p parts) events.LambdaFunctionURLRequest {
+	return events.LambdaFunctionURLRequest{
+		Version:               "2.0",
+		RawPath:               p.rawPath,
+		RawQueryString:        p.rawQueryString,
+		Cookies:               p.cookies,
+		Headers:               p.headers,
+		QueryStringParameters: p.queryStringParameters,
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			AccountID:    "anonymous",
+			RequestID:    p.requestID,
+			APIID:        p.domainPrefix,
+			DomainName:   p.domainName,
+			DomainPrefix: p.domainPrefix,
+			Time:         p.time.Format(requestTimeLayout),
+			TimeEpoch:    p.time.UnixMilli(),
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method:    p.method,
+				Path:      p.path,
+				Protocol:  p.protocol,
+				SourceIP:  p.sourceIP,
+				UserAgent: p.userAgent,
+			},
+		},
+		Body:            p.body,
+		IsBase64Encoded: p.isBase64Encoded,
+	}
+}
+
+func newHTTPAPIRequest(p parts) events.APIGatewayV2HTTPRequest {
+	return events.APIGatewayV2HTTPRequest{
+		Version:               "2.0",
+		RawPath:               p.rawPath,
+		RawQueryString:        p.rawQueryString,
+		Cookies:               p.cookies,
+		Headers:               p.headers,
+		QueryStringParameters: p.queryStringParameters,
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			AccountID:    "anonymous",
+			Stage:        "$default",
+			RequestID:    p.requestID,
+			APIID:        p.domainPrefix,
+			DomainName:   p.domainName,
+			DomainPrefix: p.domainPrefix,
+			Time:         p.time.Format(requestTimeLayout),
+			TimeEpoch:    p.time.UnixMilli(),
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method:    p.method,
+				Path:      p.path,
+				Protocol:  p.protocol,
+				SourceIP:  p.sourceIP,
+				UserAgent: p.userAgent,
+			},
+		},
+		Body:            p.body,
+		IsBase64Encoded: p.isBase64Encoded,
+	}
+}
+
+// flushWriter flushes after every write so that streaming responses reach the client immediately.
+type flushWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (f *flushWriter) Write(p []byte) (n int, err error) {
+	if n, err = f.w.Write(p); err == nil {
+		_ = f.rc.Flush()
+	}
+
+	return
+}
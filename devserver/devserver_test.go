@@ -0,0 +1,158 @@
+package devserver
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/nguyengg/golambda/lambdafunctionurl"
+	"github.com/nguyengg/golambda/start"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFunctionURL(t *testing.T) {
+	var got events.LambdaFunctionURLRequest
+	var lc *lambdacontext.LambdaContext
+	h := FunctionURL(func(ctx context.Context, request events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+		got = request
+		lc, _ = lambdacontext.FromContext(ctx)
+		return events.LambdaFunctionURLResponse{
+			StatusCode:      http.StatusCreated,
+			Headers:         map[string]string{"Content-Type": "application/octet-stream"},
+			Body:            base64.StdEncoding.EncodeToString([]byte{1, 2, 3}),
+			IsBase64Encoded: true,
+			Cookies:         []string{"a=b", "c=d"},
+		}, nil
+	}, start.DisableMetricsLogging())
+
+	req := httptest.NewRequest(http.MethodPost, "/hello/world?a=1&a=2&b=3", strings.NewReader(`{"hello":"world"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Add("X-Multi", "1")
+	req.Header.Add("X-Multi", "2")
+	req.Header.Set("Cookie", "session=abc; theme=dark")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got.RawPath != "/hello/world" || got.RawQueryString != "a=1&a=2&b=3" {
+		t.Errorf("FunctionURL() path = %s, query = %s", got.RawPath, got.RawQueryString)
+	}
+	if want := map[string]string{"a": "1,2", "b": "3"}; !reflect.DeepEqual(got.QueryStringParameters, want) {
+		t.Errorf("FunctionURL() query = %v, want %v", got.QueryStringParameters, want)
+	}
+	if want := []string{"session=abc", "theme=dark"}; !reflect.DeepEqual(got.Cookies, want) {
+		t.Errorf("FunctionURL() cookies = %v, want %v", got.Cookies, want)
+	}
+	if v, ok := got.Headers["cookie"]; ok {
+		t.Errorf("FunctionURL() cookie header should be removed, got %s", v)
+	}
+	if got.Headers["x-multi"] != "1,2" {
+		t.Errorf("FunctionURL() x-multi = %s, want 1,2", got.Headers["x-multi"])
+	}
+	if got.Body != `{"hello":"world"}` || got.IsBase64Encoded {
+		t.Errorf("FunctionURL() body = %s, isBase64Encoded = %v", got.Body, got.IsBase64Encoded)
+	}
+	if got.RequestContext.HTTP.Method != http.MethodPost || got.RequestContext.RequestID == "" {
+		t.Errorf("FunctionURL() requestContext = %#v", got.RequestContext)
+	}
+	if lc == nil || lc.AwsRequestID != got.RequestContext.RequestID {
+		t.Errorf("FunctionURL() lambdacontext = %#v", lc)
+	}
+
+	res := rec.Result()
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusCreated || !reflect.DeepEqual(body, []byte{1, 2, 3}) {
+		t.Errorf("FunctionURL() status = %d, body = %v", res.StatusCode, body)
+	}
+	if want := []string{"a=b", "c=d"}; !reflect.DeepEqual(res.Header.Values("Set-Cookie"), want) {
+		t.Errorf("FunctionURL() Set-Cookie = %v, want %v", res.Header.Values("Set-Cookie"), want)
+	}
+}
+
+func TestFunctionURLStreaming(t *testing.T) {
+	h := FunctionURLStreaming(lambdafunctionurl.NewStreamingHandler(func(c lambdafunctionurl.Context) error {
+		if !c.Request().IsBase64Encoded {
+			t.Errorf("FunctionURLStreaming() binary body should be base64-encoded")
+		}
+		c.SetResponseHeader("Content-Type", "text/plain")
+		return c.RespondOKWithBody(strings.NewReader("hello, world!"))
+	}), start.DisableMetricsLogging())
+
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("binary"))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "hello, world!" || !rec.Flushed {
+		t.Errorf("FunctionURLStreaming() status = %d, body = %s, flushed = %v", rec.Code, rec.Body.String(), rec.Flushed)
+	}
+}
+
+func TestHTTPAPI(t *testing.T) {
+	routes := []string{"ANY /files/{proxy+}", "GET /users/{id}", "GET /users/me", "POST /users/{id}"}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		wantStatusCode int
+		wantRouteKey   string
+		wantParams     map[string]string
+	}{
+		{
+			name:           "exact match wins over path parameter",
+			method:         http.MethodGet,
+			path:           "/users/me",
+			wantStatusCode: http.StatusOK,
+			wantRouteKey:   "GET /users/me",
+		},
+		{
+			name:           "path parameter",
+			method:         http.MethodPost,
+			path:           "/users/123",
+			wantStatusCode: http.StatusOK,
+			wantRouteKey:   "POST /users/{id}",
+			wantParams:     map[string]string{"id": "123"},
+		},
+		{
+			name:           "greedy path parameter",
+			method:         http.MethodDelete,
+			path:           "/files/a/b/c.txt",
+			wantStatusCode: http.StatusOK,
+			wantRouteKey:   "ANY /files/{proxy+}",
+			wantParams:     map[string]string{"proxy": "a/b/c.txt"},
+		},
+		{
+			name:           "no match",
+			method:         http.MethodDelete,
+			path:           "/users/123",
+			wantStatusCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got events.APIGatewayV2HTTPRequest
+			h := HTTPAPI(func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+				got = request
+				return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusOK}, nil
+			}, routes, start.DisableMetricsLogging())
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rec.Code != tt.wantStatusCode {
+				t.Errorf("HTTPAPI() status = %d, want %d", rec.Code, tt.wantStatusCode)
+			}
+			if got.RouteKey != tt.wantRouteKey {
+				t.Errorf("HTTPAPI() routeKey = %s, want %s", got.RouteKey, tt.wantRouteKey)
+			}
+			if !reflect.DeepEqual(got.PathParameters, tt.wantParams) {
+				t.Errorf("HTTPAPI() pathParameters = %v, want %v", got.PathParameters, tt.wantParams)
+			}
+		})
+	}
+}
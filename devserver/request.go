@@ -0,0 +1,184 @@
+package devserver
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// requestTimeLayout is the layout of the "time" field in the request context.
+const requestTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// parts contains the fields that are shared between events.LambdaFunctionURLRequest and
+// events.APIGatewayV2HTTPRequest.
+type parts struct {
+	requestID             string
+	rawPath               string
+	rawQueryString        string
+	cookies               []string
+	headers               map[string]string
+	queryStringParameters map[string]string
+	domainName            string
+	domainPrefix          string
+	time                  time.Time
+	method                string
+	path                  string
+	protocol              string
+	sourceIP              string
+	userAgent             string
+	body                  string
+	isBase64Encoded       bool
+}
+
+// parse translates the http.Request into parts.
+//
+// Header names are lower-cased, and multiple values of the same header or query parameter are joined by ",". The
+// "Cookie" header is removed in favour of the cookies field. The body is base64-encoded unless its "Content-Type" is
+// textual.
+func parse(r *http.Request) (p parts, err error) {
+	if p.requestID, err = newRequestID(); err != nil {
+		return p, err
+	}
+
+	p.rawPath = r.URL.EscapedPath()
+	p.rawQueryString = r.URL.RawQuery
+	p.time = time.Now()
+	p.method = r.Method
+	p.path = r.URL.Path
+	p.protocol = r.Proto
+	p.userAgent = r.UserAgent()
+
+	p.domainName = r.Host
+	p.domainPrefix, _, _ = strings.Cut(r.Host, ".")
+	p.domainPrefix, _, _ = strings.Cut(p.domainPrefix, ":")
+
+	p.sourceIP = r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		p.sourceIP = host
+	}
+
+	p.headers = make(map[string]string, len(r.Header))
+	for k, vs := range r.Header {
+		k = strings.ToLower(k)
+		if k == "cookie" {
+			for _, v := range vs {
+				for _, c := range strings.Split(v, ";") {
+					if c = strings.TrimSpace(c); c != "" {
+						p.cookies = append(p.cookies, c)
+					}
+				}
+			}
+			continue
+		}
+
+		p.headers[k] = strings.Join(vs, ",")
+	}
+	if r.Host != "" {
+		p.headers["host"] = r.Host
+	}
+
+	if query := r.URL.Query(); len(query) != 0 {
+		p.queryStringParameters = make(map[string]string, len(query))
+		for k, vs := range query {
+			p.queryStringParameters[k] = strings.Join(vs, ",")
+		}
+	}
+
+	if r.Body != nil {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return p, fmt.Errorf("read request body: %w", err)
+		}
+
+		switch {
+		case len(data) == 0:
+		case isText(r.Header.Get("Content-Type")):
+			p.body = string(data)
+		default:
+			p.body = base64.StdEncoding.EncodeToString(data)
+			p.isBase64Encoded = true
+		}
+	}
+
+	return p, nil
+}
+
+// isText returns true if the content type should be passed to the handler as plain text instead of base64-encoded.
+func isText(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+
+	t, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(t, "text/"),
+		strings.HasSuffix(t, "+json"),
+		strings.HasSuffix(t, "+xml"):
+		return true
+	}
+
+	switch t {
+	case "application/json", "application/xml", "application/javascript", "application/x-www-form-urlencoded":
+		return true
+	}
+
+	return false
+}
+
+// newRequestID creates a random UUID-like request Id.
+func newRequestID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate request Id: %w", err)
+	}
+
+	s := hex.EncodeToString(b[:])
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:], nil
+}
+
+// writeResponse writes the common parts of the Lambda response to the http.ResponseWriter.
+func writeResponse(w http.ResponseWriter, statusCode int, headers map[string]string, multiValueHeaders map[string][]string, cookies []string, body string, isBase64Encoded bool) {
+	data := []byte(body)
+	if isBase64Encoded {
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(body); err != nil {
+			http.Error(w, fmt.Sprintf("decode base64 response body: %v", err), http.StatusBadGateway)
+			return
+		}
+	}
+
+	writeHeader(w, statusCode, headers, multiValueHeaders, cookies)
+	_, _ = w.Write(data)
+}
+
+// writeHeader writes the status code, headers, and cookies to the http.ResponseWriter.
+func writeHeader(w http.ResponseWriter, statusCode int, headers map[string]string, multiValueHeaders map[string][]string, cookies []string) {
+	header := w.Header()
+	for k, vs := range multiValueHeaders {
+		for _, v := range vs {
+			header.Add(k, v)
+		}
+	}
+	for k, v := range headers {
+		header.Set(k, v)
+	}
+	for _, c := range cookies {
+		header.Add("Set-Cookie", c)
+	}
+
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	w.WriteHeader(statusCode)
+}
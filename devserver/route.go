@@ -0,0 +1,133 @@
+package devserver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultRouteKey is the route key that matches any request that doesn't match another route.
+const DefaultRouteKey = "$default"
+
+// route is a parsed API Gateway route key such as "GET /users/{id}" or "ANY /files/{proxy+}".
+type route struct {
+	key      string
+	method   string
+	segments []string
+	literals int
+	greedy   bool
+}
+
+// routes is a route table sorted so that the most specific route comes first.
+type routes []route
+
+// parseRoutes parses the route keys into a route table.
+//
+// Route keys have format "METHOD /path" where METHOD can be ANY, and the path can contain path parameters "{name}" as
+// well as one greedy path parameter "{name+}" at the end. The special route key "$default" matches any request. If
+// no route keys are given, "$default" is used.
+func parseRoutes(keys []string) (routes, error) {
+	if len(keys) == 0 {
+		keys = []string{DefaultRouteKey}
+	}
+
+	rs := make(routes, 0, len(keys))
+	for _, key := range keys {
+		if key == DefaultRouteKey {
+			rs = append(rs, route{key: key})
+			continue
+		}
+
+		method, path, ok := strings.Cut(key, " ")
+		if !ok || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf(`route key "%s" is not in format "METHOD /path"`, key)
+		}
+
+		r := route{key: key, method: strings.ToUpper(method), segments: strings.Split(path, "/")[1:]}
+		for i, s := range r.segments {
+			switch {
+			case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "+}"):
+				if i != len(r.segments)-1 {
+					return nil, fmt.Errorf(`route key "%s" has greedy path parameter that is not the last segment`, key)
+				}
+				r.greedy = true
+			case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}"):
+			default:
+				r.literals++
+			}
+		}
+
+		rs = append(rs, r)
+	}
+
+	// API Gateway prioritises exact matches, then routes with path parameters, then greedy routes, and finally $default.
+	sort.SliceStable(rs, func(i, j int) bool {
+		a, b := rs[i], rs[j]
+		if (a.key == DefaultRouteKey) != (b.key == DefaultRouteKey) {
+			return b.key == DefaultRouteKey
+		}
+		if a.greedy != b.greedy {
+			return b.greedy
+		}
+		if a.literals != b.literals {
+			return a.literals > b.literals
+		}
+		return a.method != "ANY" && b.method == "ANY"
+	})
+
+	return rs, nil
+}
+
+// match returns the route key and path parameters of the first route that matches the given method and path.
+func (rs routes) match(method, path string) (string, map[string]string, bool) {
+	segments := strings.Split(path, "/")[1:]
+
+	for _, r := range rs {
+		if r.key == DefaultRouteKey {
+			return r.key, nil, true
+		}
+
+		if r.method != "ANY" && r.method != method {
+			continue
+		}
+
+		if params, ok := r.matchSegments(segments); ok {
+			return r.key, params, true
+		}
+	}
+
+	return "", nil, false
+}
+
+func (r route) matchSegments(segments []string) (map[string]string, bool) {
+	if len(segments) < len(r.segments) || (!r.greedy && len(segments) != len(r.segments)) {
+		return nil, false
+	}
+
+	var params map[string]string
+	for i, s := range r.segments {
+		switch {
+		case r.greedy && i == len(r.segments)-1:
+			v := strings.Join(segments[i:], "/")
+			if v == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[s[1:len(s)-2]] = v
+		case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}"):
+			if segments[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[s[1:len(s)-1]] = segments[i]
+		case s != segments[i]:
+			return nil, false
+		}
+	}
+
+	return params, true
+}
@@ -7,53 +7,142 @@ import (
 )
 
 // StreamToDynamoDBAttributeValue converts a DynamoDB Stream event attribute to an equivalent DynamoDB attribute.
-// TODO replace recursive implementation.
-func StreamToDynamoDBAttributeValue(av events.DynamoDBAttributeValue) dynamodbtypes.AttributeValue {
-	switch av.DataType() {
-	case events.DataTypeBinary:
-		return &dynamodbtypes.AttributeValueMemberB{Value: av.Binary()}
-	case events.DataTypeBoolean:
-		return &dynamodbtypes.AttributeValueMemberBOOL{Value: av.Boolean()}
-	case events.DataTypeBinarySet:
-		return &dynamodbtypes.AttributeValueMemberBS{Value: av.BinarySet()}
-	case events.DataTypeList:
-		l := av.List()
-		value := make([]dynamodbtypes.AttributeValue, len(l))
-		for i, v := range l {
-			value[i] = StreamToDynamoDBAttributeValue(v)
-		}
-		return &dynamodbtypes.AttributeValueMemberL{Value: value}
-	case events.DataTypeMap:
-		value := make(map[string]dynamodbtypes.AttributeValue)
-		for k, v := range av.Map() {
-			value[k] = StreamToDynamoDBAttributeValue(v)
+//
+// Nested lists and maps are converted iteratively so there is no limit on how deep they can be. Returns an
+// UnsupportedDynamoDBTypeError if the attribute or any of its nested attributes has an unknown data type.
+func StreamToDynamoDBAttributeValue(av events.DynamoDBAttributeValue) (res dynamodbtypes.AttributeValue, err error) {
+	type frame struct {
+		av  events.DynamoDBAttributeValue
+		set func(dynamodbtypes.AttributeValue)
+	}
+
+	stack := []frame{{av: av, set: func(v dynamodbtypes.AttributeValue) { res = v }}}
+	for len(stack) > 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch f.av.DataType() {
+		case events.DataTypeBinary:
+			f.set(&dynamodbtypes.AttributeValueMemberB{Value: f.av.Binary()})
+		case events.DataTypeBoolean:
+			f.set(&dynamodbtypes.AttributeValueMemberBOOL{Value: f.av.Boolean()})
+		case events.DataTypeBinarySet:
+			f.set(&dynamodbtypes.AttributeValueMemberBS{Value: f.av.BinarySet()})
+		case events.DataTypeList:
+			l := f.av.List()
+			value := make([]dynamodbtypes.AttributeValue, len(l))
+			for i, v := range l {
+				stack = append(stack, frame{av: v, set: func(v dynamodbtypes.AttributeValue) { value[i] = v }})
+			}
+			f.set(&dynamodbtypes.AttributeValueMemberL{Value: value})
+		case events.DataTypeMap:
+			m := f.av.Map()
+			value := make(map[string]dynamodbtypes.AttributeValue, len(m))
+			for k, v := range m {
+				stack = append(stack, frame{av: v, set: func(v dynamodbtypes.AttributeValue) { value[k] = v }})
+			}
+			f.set(&dynamodbtypes.AttributeValueMemberM{Value: value})
+		case events.DataTypeNumber:
+			f.set(&dynamodbtypes.AttributeValueMemberN{Value: f.av.Number()})
+		case events.DataTypeNumberSet:
+			f.set(&dynamodbtypes.AttributeValueMemberNS{Value: f.av.NumberSet()})
+		case events.DataTypeNull:
+			f.set(&dynamodbtypes.AttributeValueMemberNULL{Value: f.av.IsNull()})
+		case events.DataTypeString:
+			f.set(&dynamodbtypes.AttributeValueMemberS{Value: f.av.String()})
+		case events.DataTypeStringSet:
+			f.set(&dynamodbtypes.AttributeValueMemberSS{Value: f.av.StringSet()})
+		default:
+			return nil, UnsupportedDynamoDBTypeError{DataType: f.av.DataType()}
 		}
-		return &dynamodbtypes.AttributeValueMemberM{Value: value}
-	case events.DataTypeNumber:
-		return &dynamodbtypes.AttributeValueMemberN{Value: av.Number()}
-	case events.DataTypeNumberSet:
-		return &dynamodbtypes.AttributeValueMemberNS{Value: av.NumberSet()}
-	case events.DataTypeNull:
-		return &dynamodbtypes.AttributeValueMemberNULL{Value: av.IsNull()}
-	case events.DataTypeString:
-		return &dynamodbtypes.AttributeValueMemberS{Value: av.String()}
-	case events.DataTypeStringSet:
-		return &dynamodbtypes.AttributeValueMemberSS{Value: av.StringSet()}
-	default:
-		panic(UnsupportedDynamoDBTypeError{DataType: av.DataType()})
 	}
+
+	return res, nil
 }
 
 // StreamToDynamoDBItem uses StreamToDynamoDBAttributeValue to convert an item from a DynamoDB Stream event to an item in
 // DynamoDB.
-func StreamToDynamoDBItem(item map[string]events.DynamoDBAttributeValue) map[string]dynamodbtypes.AttributeValue {
-	res := make(map[string]dynamodbtypes.AttributeValue)
+func StreamToDynamoDBItem(item map[string]events.DynamoDBAttributeValue) (map[string]dynamodbtypes.AttributeValue, error) {
+	res := make(map[string]dynamodbtypes.AttributeValue, len(item))
 	for k, v := range item {
-		res[k] = StreamToDynamoDBAttributeValue(v)
+		av, err := StreamToDynamoDBAttributeValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("convert attribute %s: %w", k, err)
+		}
+		res[k] = av
 	}
-	return res
+	return res, nil
 }
 
+// DynamoDBToStreamAttributeValue is the reverse of StreamToDynamoDBAttributeValue, converting a DynamoDB attribute to an
+// equivalent DynamoDB Stream event attribute.
+//
+// This is mostly useful for creating DynamoDB Stream events in tests. Returns an UnsupportedAttributeValueError if the
+// attribute or any of its nested attributes has an unknown type.
+func DynamoDBToStreamAttributeValue(av dynamodbtypes.AttributeValue) (res events.DynamoDBAttributeValue, err error) {
+	type frame struct {
+		av  dynamodbtypes.AttributeValue
+		set func(events.DynamoDBAttributeValue)
+	}
+
+	stack := []frame{{av: av, set: func(v events.DynamoDBAttributeValue) { res = v }}}
+	for len(stack) > 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch v := f.av.(type) {
+		case *dynamodbtypes.AttributeValueMemberB:
+			f.set(events.NewBinaryAttribute(v.Value))
+		case *dynamodbtypes.AttributeValueMemberBOOL:
+			f.set(events.NewBooleanAttribute(v.Value))
+		case *dynamodbtypes.AttributeValueMemberBS:
+			f.set(events.NewBinarySetAttribute(v.Value))
+		case *dynamodbtypes.AttributeValueMemberL:
+			value := make([]events.DynamoDBAttributeValue, len(v.Value))
+			for i, e := range v.Value {
+				stack = append(stack, frame{av: e, set: func(v events.DynamoDBAttributeValue) { value[i] = v }})
+			}
+			f.set(events.NewListAttribute(value))
+		case *dynamodbtypes.AttributeValueMemberM:
+			value := make(map[string]events.DynamoDBAttributeValue, len(v.Value))
+			for k, e := range v.Value {
+				stack = append(stack, frame{av: e, set: func(v events.DynamoDBAttributeValue) { value[k] = v }})
+			}
+			f.set(events.NewMapAttribute(value))
+		case *dynamodbtypes.AttributeValueMemberN:
+			f.set(events.NewNumberAttribute(v.Value))
+		case *dynamodbtypes.AttributeValueMemberNS:
+			f.set(events.NewNumberSetAttribute(v.Value))
+		case *dynamodbtypes.AttributeValueMemberNULL:
+			f.set(events.NewNullAttribute())
+		case *dynamodbtypes.AttributeValueMemberS:
+			f.set(events.NewStringAttribute(v.Value))
+		case *dynamodbtypes.AttributeValueMemberSS:
+			f.set(events.NewStringSetAttribute(v.Value))
+		default:
+			return res, UnsupportedAttributeValueError{Value: f.av}
+		}
+	}
+
+	return res, nil
+}
+
+// DynamoDBToStreamItem uses DynamoDBToStreamAttributeValue to convert an item in DynamoDB to an item from a DynamoDB
+// Stream event.
+func DynamoDBToStreamItem(item map[string]dynamodbtypes.AttributeValue) (map[string]events.DynamoDBAttributeValue, error) {
+	res := make(map[string]events.DynamoDBAttributeValue, len(item))
+	for k, v := range item {
+		av, err := DynamoDBToStreamAttributeValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("convert attribute %s: %w", k, err)
+		}
+		res[k] = av
+	}
+	return res, nil
+}
+
+// UnsupportedDynamoDBTypeError is returned by StreamToDynamoDBAttributeValue if the DynamoDB Stream event attribute has
+// an unknown data type.
 type UnsupportedDynamoDBTypeError struct {
 	DataType events.DynamoDBDataType
 }
@@ -61,3 +150,13 @@ type UnsupportedDynamoDBTypeError struct {
 func (e UnsupportedDynamoDBTypeError) Error() string {
 	return fmt.Sprintf("unsupported DynamoDB attribute type, %v", e.DataType)
 }
+
+// UnsupportedAttributeValueError is returned by DynamoDBToStreamAttributeValue if the DynamoDB attribute has an unknown
+// type.
+type UnsupportedAttributeValueError struct {
+	Value dynamodbtypes.AttributeValue
+}
+
+func (e UnsupportedAttributeValueError) Error() string {
+	return fmt.Sprintf("unsupported DynamoDB attribute value type, %T", e.Value)
+}
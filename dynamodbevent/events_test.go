@@ -1,6 +1,7 @@
 package dynamodbevent
 
 import (
+	"errors"
 	"github.com/aws/aws-lambda-go/events"
 	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"reflect"
@@ -37,9 +38,55 @@ func TestStreamToDynamoDBItem_success(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := StreamToDynamoDBItem(tt.args.item); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("StreamToDynamoDBItem() = %v, want %v", got, tt.want)
+			if got, err := StreamToDynamoDBItem(tt.args.item); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StreamToDynamoDBItem() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
 }
+
+func TestDynamoDBToStreamItem_roundTrip(t *testing.T) {
+	// deeply nested lists and maps must not overflow the stack.
+	var nested dynamodbtypes.AttributeValue = &dynamodbtypes.AttributeValueMemberS{Value: "leaf"}
+	for i := 0; i < 10000; i++ {
+		if i%2 == 0 {
+			nested = &dynamodbtypes.AttributeValueMemberL{Value: []dynamodbtypes.AttributeValue{nested, &dynamodbtypes.AttributeValueMemberN{Value: "1"}}}
+		} else {
+			nested = &dynamodbtypes.AttributeValueMemberM{Value: map[string]dynamodbtypes.AttributeValue{"child": nested}}
+		}
+	}
+
+	item := map[string]dynamodbtypes.AttributeValue{
+		"binary":    &dynamodbtypes.AttributeValueMemberB{Value: []byte("hello")},
+		"bool":      &dynamodbtypes.AttributeValueMemberBOOL{Value: true},
+		"binarySet": &dynamodbtypes.AttributeValueMemberBS{Value: [][]byte{[]byte("a"), []byte("b")}},
+		"null":      &dynamodbtypes.AttributeValueMemberNULL{Value: true},
+		"numberSet": &dynamodbtypes.AttributeValueMemberNS{Value: []string{"1", "2"}},
+		"stringSet": &dynamodbtypes.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		"nested":    nested,
+	}
+
+	streamItem, err := DynamoDBToStreamItem(item)
+	if err != nil {
+		t.Fatalf("DynamoDBToStreamItem() error = %v", err)
+	}
+
+	got, err := StreamToDynamoDBItem(streamItem)
+	if err != nil {
+		t.Fatalf("StreamToDynamoDBItem() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, item) {
+		t.Errorf("StreamToDynamoDBItem(DynamoDBToStreamItem()) does not round-trip")
+	}
+}
+
+func TestDynamoDBToStreamItem_unsupported(t *testing.T) {
+	_, err := DynamoDBToStreamItem(map[string]dynamodbtypes.AttributeValue{
+		"list": &dynamodbtypes.AttributeValueMemberL{Value: []dynamodbtypes.AttributeValue{&dynamodbtypes.UnknownUnionMember{Tag: "X"}}},
+	})
+
+	var ue UnsupportedAttributeValueError
+	if !errors.As(err, &ue) {
+		t.Errorf("DynamoDBToStreamItem() error = %v, want UnsupportedAttributeValueError", err)
+	}
+}
@@ -0,0 +1,136 @@
+package dynamodbevent
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/nguyengg/golambda/metrics"
+	"github.com/nguyengg/golambda/start"
+	"github.com/nguyengg/golambda/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CounterKeyDecodeFailure is the counter that is incremented for every record whose images cannot be unmarshalled.
+const CounterKeyDecodeFailure = "decodeFailureCount"
+
+// EventName is the type of data modification that was performed on the DynamoDB table.
+type EventName string
+
+const (
+	// EventNameInsert indicates a new item was added to the table.
+	EventNameInsert EventName = "INSERT"
+	// EventNameModify indicates one or more of an existing item's attributes were modified.
+	EventNameModify EventName = "MODIFY"
+	// EventNameRemove indicates the item was deleted from the table.
+	EventNameRemove EventName = "REMOVE"
+)
+
+// Record is a DynamoDB Stream record whose images have been unmarshalled into T.
+type Record[T any] struct {
+	// EventName is the type of data modification.
+	EventName EventName
+	// OldImage is the item before it was modified.
+	//
+	// Nil for EventNameInsert, or if the stream view type doesn't include old images.
+	OldImage *T
+	// NewImage is the item after it was modified.
+	//
+	// Nil for EventNameRemove, or if the stream view type doesn't include new images.
+	NewImage *T
+	// Raw is the original record.
+	Raw events.DynamoDBEventRecord
+}
+
+// RecordHandler is the handler for individual DynamoDB Stream records. See StartRecordHandler.
+type RecordHandler[T any] func(ctx context.Context, record Record[T]) error
+
+// StartRecordHandler handles the generation of events.DynamoDBEventResponse for caller.
+//
+// See NewRecordHandler for how records are processed.
+func StartRecordHandler[T any](handler RecordHandler[T], options ...start.Option) {
+	lambda.Start(WrapRecordHandler(handler, options...))
+}
+
+// WrapRecordHandler returns the handler that StartRecordHandler passes to the Lambda runtime.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. in tests.
+func WrapRecordHandler[T any](handler RecordHandler[T], options ...start.Option) HandlerWithResponse {
+	return WrapHandlerWithResponse(NewRecordHandler(handler), options...)
+}
+
+// NewRecordHandler converts a RecordHandler into a HandlerWithResponse that unmarshals the images of every record into
+// T using attributevalue.UnmarshalMap.
+//
+// Records are processed serially in order. When a record fails (either its images cannot be unmarshalled, in which
+// case the CounterKeyDecodeFailure counter is incremented, or the RecordHandler returns a non-nil error), an
+// events.DynamoDBBatchItemFailure is created with the record's sequence number, and the remaining records are skipped
+// because Lambda will retry the batch starting from the failed record anyway.
+func NewRecordHandler[T any](handler RecordHandler[T]) HandlerWithResponse {
+	return func(ctx context.Context, request events.DynamoDBEvent) (response events.DynamoDBEventResponse, err error) {
+		for _, record := range request.Records {
+			if err := process(ctx, handler, record); err != nil {
+				response.BatchItemFailures = append(response.BatchItemFailures, events.DynamoDBBatchItemFailure{ItemIdentifier: record.Change.SequenceNumber})
+				break
+			}
+		}
+
+		return
+	}
+}
+
+// process unmarshals the images of a single record and invokes the handler in the record's own span.
+func process[T any](ctx context.Context, handler RecordHandler[T], record events.DynamoDBEventRecord) (err error) {
+	panicked := true
+
+	ctx, span := tracing.StartRecord(ctx, "record",
+		attribute.String("eventId", record.EventID),
+		attribute.String("eventName", record.EventName))
+	defer func() {
+		tracing.End(span, err, panicked)
+	}()
+
+	r, err := newRecord[T](record)
+	if err != nil {
+		metrics.Ctx(ctx).IncrementCount(CounterKeyDecodeFailure)
+	} else {
+		err = handler(ctx, r)
+	}
+
+	panicked = false
+	return
+}
+
+func newRecord[T any](record events.DynamoDBEventRecord) (r Record[T], err error) {
+	r.EventName = EventName(record.EventName)
+	r.Raw = record
+
+	if r.OldImage, err = unmarshalImage[T](record.Change.OldImage); err != nil {
+		return r, fmt.Errorf("unmarshal old image of record %s: %w", record.EventID, err)
+	}
+
+	if r.NewImage, err = unmarshalImage[T](record.Change.NewImage); err != nil {
+		return r, fmt.Errorf("unmarshal new image of record %s: %w", record.EventID, err)
+	}
+
+	return r, nil
+}
+
+func unmarshalImage[T any](image map[string]events.DynamoDBAttributeValue) (*T, error) {
+	if len(image) == 0 {
+		return nil, nil
+	}
+
+	item, err := StreamToDynamoDBItem(image)
+	if err != nil {
+		return nil, err
+	}
+
+	v := new(T)
+	if err = attributevalue.UnmarshalMap(item, v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
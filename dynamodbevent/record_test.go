@@ -0,0 +1,86 @@
+package dynamodbevent
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"reflect"
+	"testing"
+)
+
+func TestNewRecordHandler(t *testing.T) {
+	type Item struct {
+		Id      string `dynamodbav:"id"`
+		Version int    `dynamodbav:"version"`
+	}
+
+	newRecord := func(sequenceNumber string, eventName EventName, oldImage, newImage map[string]events.DynamoDBAttributeValue) events.DynamoDBEventRecord {
+		return events.DynamoDBEventRecord{
+			EventID:        sequenceNumber,
+			EventName:      string(eventName),
+			EventSourceArn: "arn:aws:dynamodb:us-east-1:123456789012:table/my-table/stream/2006-01-02T15:04:05.000",
+			Change: events.DynamoDBStreamRecord{
+				SequenceNumber: sequenceNumber,
+				OldImage:       oldImage,
+				NewImage:       newImage,
+			},
+		}
+	}
+	image := func(id, version string) map[string]events.DynamoDBAttributeValue {
+		return map[string]events.DynamoDBAttributeValue{
+			"id":      events.NewStringAttribute(id),
+			"version": events.NewNumberAttribute(version),
+		}
+	}
+
+	var got []Record[Item]
+	h := NewRecordHandler(func(ctx context.Context, record Record[Item]) error {
+		got = append(got, record)
+		if record.NewImage != nil && record.NewImage.Id == "fail" {
+			return errors.New("fail")
+		}
+		return nil
+	})
+
+	// every record after the first failure must be skipped.
+	response, err := h(context.Background(), events.DynamoDBEvent{Records: []events.DynamoDBEventRecord{
+		newRecord("1", EventNameInsert, nil, image("a", "1")),
+		newRecord("2", EventNameModify, image("b", "1"), image("fail", "2")),
+		newRecord("3", EventNameModify, image("a", "1"), image("a", "2")),
+		newRecord("4", EventNameRemove, image("b", "2"), nil),
+	}})
+	if err != nil {
+		t.Fatalf("NewRecordHandler() error = %v", err)
+	}
+
+	if want := []events.DynamoDBBatchItemFailure{{ItemIdentifier: "2"}}; !reflect.DeepEqual(response.BatchItemFailures, want) {
+		t.Errorf("NewRecordHandler() failures = %v, want %v", response.BatchItemFailures, want)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("NewRecordHandler() invoked handler %d times, want 2", len(got))
+	}
+	if r := got[0]; r.EventName != EventNameInsert || r.OldImage != nil || !reflect.DeepEqual(r.NewImage, &Item{Id: "a", Version: 1}) {
+		t.Errorf("NewRecordHandler() got[0] = %#v", r)
+	}
+	if r := got[1]; r.EventName != EventNameModify || !reflect.DeepEqual(r.OldImage, &Item{Id: "b", Version: 1}) || r.Raw.Change.SequenceNumber != "2" {
+		t.Errorf("NewRecordHandler() got[1] = %#v", r)
+	}
+
+	// a record that cannot be unmarshalled fails without invoking the handler.
+	got = nil
+	response, err = h(context.Background(), events.DynamoDBEvent{Records: []events.DynamoDBEventRecord{
+		newRecord("5", EventNameModify, image("a", "2"), map[string]events.DynamoDBAttributeValue{"version": events.NewStringAttribute("not a number")}),
+		newRecord("6", EventNameRemove, image("a", "2"), nil),
+	}})
+	if err != nil {
+		t.Fatalf("NewRecordHandler() error = %v", err)
+	}
+
+	if want := []events.DynamoDBBatchItemFailure{{ItemIdentifier: "5"}}; !reflect.DeepEqual(response.BatchItemFailures, want) {
+		t.Errorf("NewRecordHandler() failures = %v, want %v", response.BatchItemFailures, want)
+	}
+	if len(got) != 0 {
+		t.Errorf("NewRecordHandler() invoked handler %d times, want 0", len(got))
+	}
+}
@@ -7,8 +7,9 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/nguyengg/golambda/configsupport"
 	"github.com/nguyengg/golambda/logsupport"
-	"github.com/nguyengg/golambda/metrics"
 	"github.com/nguyengg/golambda/start"
+	"github.com/nguyengg/golambda/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"log"
 )
 
@@ -20,13 +21,21 @@ type HandlerWithResponse func(ctx context.Context, request events.DynamoDBEvent)
 
 // Start starts the Lambda runtime loop with the specified Handler.
 func Start(handler Handler, options ...start.Option) {
+	lambda.Start(Wrap(handler, options...))
+}
+
+// Wrap returns the handler that Start passes to the Lambda runtime, which is the given handler wrapped with metrics,
+// logging, and middlewares according to the options.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. in tests.
+func Wrap(handler Handler, options ...start.Option) Handler {
 	opts := start.New(options)
+	h := start.Chain(opts, func(ctx context.Context, request events.DynamoDBEvent) (struct{}, error) {
+		return struct{}{}, handler(ctx, request)
+	})
 
-	lambda.Start(func(ctx context.Context, request events.DynamoDBEvent) (err error) {
-		m := metrics.NewSimpleMetricsContext(
-			opts.LoggerProvider(ctx).WithContext(ctx),
-			"",
-			0)
+	return func(ctx context.Context, request events.DynamoDBEvent) (err error) {
+		m := opts.NewMetrics(ctx, "", 0)
 		ctx = m.WithContext(ctx)
 
 		if !opts.DisableSetUpGlobalLogger {
@@ -44,6 +53,12 @@ func Start(handler Handler, options ...start.Option) {
 
 		panicked := true
 
+		ctx, span := opts.StartSpan(ctx, "dynamodbevent",
+			attribute.Int("recordCount", len(request.Records)))
+		defer func() {
+			tracing.End(span, err, panicked)
+		}()
+
 		if !opts.DisableMetricsLogging {
 			m.AddCount("recordCount", int64(len(request.Records)))
 
@@ -59,21 +74,35 @@ func Start(handler Handler, options ...start.Option) {
 			}()
 		}
 
-		err = handler(ctx, request)
+		if opts.RecoverPanics {
+			defer func() {
+				if r := recover(); r != nil {
+					err = opts.Recover(ctx, r)
+				}
+			}()
+		}
+
+		_, err = h(ctx, request)
 		panicked = false
 		return
-	})
+	}
 }
 
 // StartHandlerWithResponse starts the Lambda runtime loop with the specified HandlerWithResponse.
 func StartHandlerWithResponse(handler HandlerWithResponse, options ...start.Option) {
+	lambda.Start(WrapHandlerWithResponse(handler, options...))
+}
+
+// WrapHandlerWithResponse returns the handler that StartHandlerWithResponse passes to the Lambda runtime, which is the
+// given handler wrapped with metrics, logging, and middlewares according to the options.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. in tests.
+func WrapHandlerWithResponse(handler HandlerWithResponse, options ...start.Option) HandlerWithResponse {
 	opts := start.New(options)
+	h := start.Chain(opts, start.Handler[events.DynamoDBEvent, events.DynamoDBEventResponse](handler))
 
-	lambda.Start(func(ctx context.Context, request events.DynamoDBEvent) (response events.DynamoDBEventResponse, err error) {
-		m := metrics.NewSimpleMetricsContext(
-			opts.LoggerProvider(ctx).WithContext(ctx),
-			"",
-			0)
+	return func(ctx context.Context, request events.DynamoDBEvent) (response events.DynamoDBEventResponse, err error) {
+		m := opts.NewMetrics(ctx, "", 0)
 		ctx = m.WithContext(ctx)
 
 		if !opts.DisableSetUpGlobalLogger {
@@ -91,9 +120,14 @@ func StartHandlerWithResponse(handler HandlerWithResponse, options ...start.Opti
 
 		panicked := true
 
+		ctx, span := opts.StartSpan(ctx, "dynamodbevent",
+			attribute.Int("recordCount", len(request.Records)))
+		defer func() {
+			tracing.End(span, err, panicked)
+		}()
+
 		if !opts.DisableMetricsLogging {
 			m.AddCount("recordCount", int64(len(request.Records)))
-			m.AddCount("batchItemFailureCount", int64(len(response.BatchItemFailures)))
 
 			defer func() {
 				if panicked {
@@ -103,12 +137,31 @@ func StartHandlerWithResponse(handler HandlerWithResponse, options ...start.Opti
 					m.Faulted()
 				}
 
-				m.Log()
+				m.SetCount("batchItemFailureCount", int64(len(response.BatchItemFailures))).Log()
 			}()
 		}
 
-		response, err = handler(ctx, request)
+		if opts.RecoverPanics {
+			defer func() {
+				if r := recover(); r != nil {
+					_ = opts.Recover(ctx, r)
+					response, err = failAll(request), nil
+				}
+			}()
+		}
+
+		response, err = h(ctx, request)
 		panicked = false
 		return
-	})
+	}
+}
+
+// failAll creates an events.DynamoDBEventResponse that reports every record as a batch item failure.
+func failAll(request events.DynamoDBEvent) events.DynamoDBEventResponse {
+	failures := make([]events.DynamoDBBatchItemFailure, len(request.Records))
+	for i, record := range request.Records {
+		failures[i] = events.DynamoDBBatchItemFailure{ItemIdentifier: record.Change.SequenceNumber}
+	}
+
+	return events.DynamoDBEventResponse{BatchItemFailures: failures}
 }
@@ -0,0 +1,273 @@
+// Package golambdatest provides utilities for testing handlers that are wrapped by the Wrap functions of the various
+// event packages such as sqsevent.Wrap or lambdafunctionurl.Wrap.
+//
+// Usage:
+//
+//	r := golambdatest.NewRecorder()
+//	h := sqsevent.WrapMessageHandler(handler, r.Options()...)
+//	response, err := h(golambdatest.NewContext(context.Background()), request)
+//	r.LastMetrics(t).AssertCounter(t, "failureCount", 0)
+package golambdatest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/nguyengg/golambda/metrics"
+	"github.com/nguyengg/golambda/start"
+	"github.com/rs/zerolog"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// InvokedFunctionArn is the [lambdacontext.LambdaContext.InvokedFunctionArn] of contexts created by NewContext.
+const InvokedFunctionArn = "arn:aws:lambda:us-east-1:000000000000:function:golambdatest"
+
+// NewContext returns a child context with a fake lambdacontext.LambdaContext that has a random AwsRequestID.
+func NewContext(parent context.Context) context.Context {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	s := hex.EncodeToString(b[:])
+
+	return NewContextWithRequestID(parent, s[0:8]+"-"+s[8:12]+"-"+s[12:16]+"-"+s[16:20]+"-"+s[20:])
+}
+
+// NewContextWithRequestID is a variant of NewContext that uses the given request Id.
+func NewContextWithRequestID(parent context.Context, requestId string) context.Context {
+	return lambdacontext.NewContext(parent, &lambdacontext.LambdaContext{
+		AwsRequestID:       requestId,
+		InvokedFunctionArn: InvokedFunctionArn,
+	})
+}
+
+// Recorder captures the zerolog output and the metrics of wrapped handlers.
+//
+// Use Options to create the start.Option instances that direct the output to the Recorder. The zero value is ready for
+// use, and a Recorder is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	logs    bytes.Buffer
+	metrics bytes.Buffer
+}
+
+// NewRecorder returns a new empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Options returns the start.Option instances that direct the logger from start.Options.LoggerProvider as well as the
+// metrics to the Recorder.
+func (r *Recorder) Options() []start.Option {
+	return []start.Option{
+		start.WithLoggerProvider(func(ctx context.Context) *zerolog.Logger {
+			l := zerolog.New(&lockedWriter{mu: &r.mu, w: &r.logs})
+			return &l
+		}),
+		start.WithMetricsOutput(&lockedWriter{mu: &r.mu, w: &r.metrics}),
+	}
+}
+
+// Logs returns the raw zerolog output.
+func (r *Recorder) Logs() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.logs.String()
+}
+
+// LogEntries returns the zerolog output decoded as one JSON object per entry.
+//
+// The test fails immediately if the output cannot be decoded.
+func (r *Recorder) LogEntries(t testing.TB) []map[string]interface{} {
+	t.Helper()
+
+	r.mu.Lock()
+	data := bytes.Clone(r.logs.Bytes())
+	r.mu.Unlock()
+
+	entries, err := decode(data)
+	if err != nil {
+		t.Fatalf("decode logs: %v", err)
+	}
+
+	return entries
+}
+
+// Metrics returns the metrics that have been logged, one entry per invocation.
+//
+// The test fails immediately if the metrics cannot be decoded.
+func (r *Recorder) Metrics(t testing.TB) []Metrics {
+	t.Helper()
+
+	r.mu.Lock()
+	data := bytes.Clone(r.metrics.Bytes())
+	r.mu.Unlock()
+
+	entries, err := decode(data)
+	if err != nil {
+		t.Fatalf("decode metrics: %v", err)
+	}
+
+	ms := make([]Metrics, len(entries))
+	for i, e := range entries {
+		ms[i] = e
+	}
+
+	return ms
+}
+
+// LastMetrics returns the metrics of the most recent invocation.
+//
+// The test fails immediately if no metrics have been logged.
+func (r *Recorder) LastMetrics(t testing.TB) Metrics {
+	t.Helper()
+
+	ms := r.Metrics(t)
+	if len(ms) == 0 {
+		t.Fatalf("no metrics have been logged")
+	}
+
+	return ms[len(ms)-1]
+}
+
+// Reset discards all captured output.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logs.Reset()
+	r.metrics.Reset()
+}
+
+// Metrics is a decoded metrics entry as written by metrics.SimpleMetrics.
+//
+// Numbers are decoded as json.Number.
+type Metrics map[string]interface{}
+
+// Counter returns the value of the counter with the given key.
+func (m Metrics) Counter(key string) (int64, bool) {
+	v, ok := m.group(metrics.ReservedKeyCounters)[key].(json.Number)
+	if !ok {
+		return 0, false
+	}
+
+	i, err := v.Int64()
+	return i, err == nil
+}
+
+// Floater returns the value of the floater with the given key.
+func (m Metrics) Floater(key string) (float64, bool) {
+	v, ok := m.group(metrics.ReservedKeyFloaters)[key].(json.Number)
+	if !ok {
+		return 0, false
+	}
+
+	f, err := v.Float64()
+	return f, err == nil
+}
+
+// Timing returns the timing statistics (sum, min, max, n, avg) with the given key.
+func (m Metrics) Timing(key string) (map[string]interface{}, bool) {
+	v, ok := m.group(metrics.ReservedKeyTimings)[key].(map[string]interface{})
+	return v, ok
+}
+
+// Property returns the value of the top-level property with the given key.
+func (m Metrics) Property(key string) (interface{}, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+// AssertCounter fails the test if the counter with the given key does not exist or has a different value.
+func (m Metrics) AssertCounter(t testing.TB, key string, want int64) {
+	t.Helper()
+
+	if got, ok := m.Counter(key); !ok {
+		t.Errorf("counter %q does not exist", key)
+	} else if got != want {
+		t.Errorf("counter %q = %d, want %d", key, got, want)
+	}
+}
+
+// AssertFloater fails the test if the floater with the given key does not exist or has a different value.
+func (m Metrics) AssertFloater(t testing.TB, key string, want float64) {
+	t.Helper()
+
+	if got, ok := m.Floater(key); !ok {
+		t.Errorf("floater %q does not exist", key)
+	} else if got != want {
+		t.Errorf("floater %q = %f, want %f", key, got, want)
+	}
+}
+
+// AssertProperty fails the test if the top-level property with the given key does not exist or has a different value.
+//
+// Numeric properties are compared by their string representation so want can be any number type.
+func (m Metrics) AssertProperty(t testing.TB, key string, want interface{}) {
+	t.Helper()
+
+	got, ok := m.Property(key)
+	switch {
+	case !ok:
+		t.Errorf("property %q does not exist", key)
+	case isNumber(got):
+		if n := got.(json.Number); !equalNumber(n, want) {
+			t.Errorf("property %q = %s, want %v", key, n, want)
+		}
+	case !reflect.DeepEqual(got, want):
+		t.Errorf("property %q = %v, want %v", key, got, want)
+	}
+}
+
+func (m Metrics) group(key string) map[string]interface{} {
+	v, _ := m[key].(map[string]interface{})
+	return v
+}
+
+func isNumber(v interface{}) bool {
+	_, ok := v.(json.Number)
+	return ok
+}
+
+func equalNumber(n json.Number, want interface{}) bool {
+	data, err := json.Marshal(want)
+	return err == nil && string(data) == n.String()
+}
+
+// decode decodes a stream of JSON objects.
+func decode(data []byte) (entries []map[string]interface{}, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	for {
+		var e map[string]interface{}
+		if err = dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, nil
+			}
+
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+}
+
+// lockedWriter serialises writes to the Recorder's buffers.
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.w.Write(p)
+}
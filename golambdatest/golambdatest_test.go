@@ -0,0 +1,64 @@
+package golambdatest_test
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/nguyengg/golambda/golambdatest"
+	"github.com/nguyengg/golambda/sqsevent"
+	"github.com/nguyengg/golambda/start"
+	"reflect"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	r := golambdatest.NewRecorder()
+	h := sqsevent.WrapMessageHandler(func(ctx context.Context, message events.SQSMessage) error {
+		switch message.MessageId {
+		case "fail":
+			return errors.New("fail")
+		case "panic":
+			panic("boom")
+		}
+		return nil
+	}, append(r.Options(), start.RecoverPanics())...)
+
+	ctx := golambdatest.NewContextWithRequestID(context.Background(), "my-request-id")
+	if lc, ok := lambdacontext.FromContext(ctx); !ok || lc.AwsRequestID != "my-request-id" {
+		t.Fatalf("NewContextWithRequestID() lambdacontext = %#v", lc)
+	}
+
+	response, err := h(ctx, events.SQSEvent{Records: []events.SQSMessage{{MessageId: "ok"}, {MessageId: "fail"}}})
+	if err != nil {
+		t.Fatalf("WrapMessageHandler() error = %v", err)
+	}
+	if want := []events.SQSBatchItemFailure{{ItemIdentifier: "fail"}}; !reflect.DeepEqual(response.BatchItemFailures, want) {
+		t.Errorf("WrapMessageHandler() failures = %v, want %v", response.BatchItemFailures, want)
+	}
+
+	m := r.LastMetrics(t)
+	m.AssertCounter(t, "recordCount", 2)
+	m.AssertCounter(t, "failureCount", 1)
+	m.AssertCounter(t, "panicked", 0)
+	m.AssertProperty(t, "lambdaRequestId", "my-request-id")
+
+	response, err = h(golambdatest.NewContext(context.Background()), events.SQSEvent{Records: []events.SQSMessage{{MessageId: "ok"}, {MessageId: "panic"}}})
+	if err != nil {
+		t.Fatalf("WrapMessageHandler() error = %v", err)
+	}
+	if len(response.BatchItemFailures) != 2 {
+		t.Errorf("WrapMessageHandler() failures = %v, want all records", response.BatchItemFailures)
+	}
+
+	if ms := r.Metrics(t); len(ms) != 2 {
+		t.Fatalf("Metrics() got %d entries, want 2", len(ms))
+	}
+	m = r.LastMetrics(t)
+	m.AssertCounter(t, "panicked", 1)
+	m.AssertCounter(t, "failureCount", 2)
+
+	if entries := r.LogEntries(t); len(entries) != 1 || entries[0]["panic"] != "boom" {
+		t.Errorf("LogEntries() = %v, want the recovered panic", entries)
+	}
+}
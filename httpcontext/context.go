@@ -0,0 +1,341 @@
+// Package httpcontext defines the Context shared by the wrapped handlers of Lambda Function URL and API Gateway HTTP API
+// requests.
+//
+// Both events use the same payload format version 2.0, so a handler that only depends on Context can be deployed
+// behind either:
+//
+//	func handle(c httpcontext.Context) error {
+//		return c.RespondOKWithText("hello, world!")
+//	}
+//
+//	lambdafunctionurl.StartWrapper(httpcontext.Adapt[lambdafunctionurl.Context](handle))
+//	framework.Start(httpcontext.Adapt[*framework.Context](handle))
+//
+// API Gateway HTTP API only supports the equivalent of the BUFFERED invoke mode of Lambda Function URL.
+package httpcontext
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/nguyengg/golambda/httpcontext/validate"
+	"github.com/nguyengg/golambda/lambdafunctionurl/cachecontrol"
+	"github.com/nguyengg/golambda/lambdafunctionurl/compress"
+	"github.com/nguyengg/golambda/lambdafunctionurl/etag"
+	"github.com/nguyengg/golambda/metrics"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultContentETagMaxSize is the default maximum number of bytes of a RESPONSE_STREAM body that is buffered to
+// compute its content-based ETag.
+const DefaultContentETagMaxSize = 1024 * 1024
+
+// Metrics emitted when a response is compressed (see Context.EnableCompression).
+const (
+	PropertyKeyContentEncoding = "contentEncoding"
+	CounterKeyUncompressedSize = "uncompressedSize"
+	CounterKeyCompressedSize   = "compressedSize"
+	FloaterKeyCompressionRatio = "compressionRatio"
+)
+
+// Context is the context passed into the wrapped handler of HTTP requests.
+//
+// Both lambdafunctionurl.Context and *framework.Context (for API Gateway HTTP API) extend it with the original event.
+type Context interface {
+	// Context returns the original context.Context of the request.
+	Context() context.Context
+	// WithValue replaces the underlying context.Context with the result from calling context.WithValue.
+	WithValue(key, value any) context.Context
+	// Value returns the value associated with the underlying context.Context that has been added with WithValue.
+	Value(key any) any
+	// Metrics returns the current metrics.Metrics instance from context.
+	Metrics() metrics.Metrics
+
+	// RequestHeaders returns the http.Header headers parsed from the original request.
+	RequestHeaders() http.Header
+	// RequestQueryValues returns the url.Values query string  parsed from the original events.lambdaFunctionURLRequest
+	// instance.
+	RequestQueryValues() url.Values
+	// RequestMethod provides a convenient method to retrieve the HTTP method of the request from its request context.
+	RequestMethod() string
+	// RequestPath provides a convenient method to retrieve the HTTP path of the request from its request context.
+	RequestPath() string
+	// RequestTimestamp returns the TimeEpoch of the request context wrapped as time.Time.
+	//
+	// Check [time.Time.IsZero] in case the TimeEpoch is missing or 0.
+	RequestTimestamp() time.Time
+	// RequestHeader returns the request header for the specified key.
+	RequestHeader(key string) string
+	// HasQueryParam returns true if the key is set. See url.Values.
+	HasQueryParam(key string) bool
+	// QueryParam returns the query parameter value for the specified key.
+	//
+	// If there are multiple values for the same key, QueryParam will return the first. Use QueryParamValues to retrieve
+	// all of them.
+	QueryParam(key string) string
+	// QueryParamValues returns the query parameter values for the specified key.
+	//
+	// Use this method if there are multiple values for the same key.
+	QueryParamValues(key string) []string
+	// QueryParamParseInt parses a query parameter value as numeric using strconv.ParseInt, passing the base and bitSize
+	// arguments. Returns the parsed numeric value, true, nil if successful.
+	//
+	// Otherwise, return the error from strconv.ParseInt.
+	QueryParamParseInt(key string, base, bitSize int) (int64, bool, error)
+	// RequestCookie returns cookie value from the request.
+	RequestCookie(key string) string
+	// ParseRequestBodyAsFormData attempts to parse the request body as application/x-www-form-urlencoded content.
+	//
+	// The method will not check if the request's content type if "application/x-www-form-urlencoded".
+	ParseRequestBodyAsFormData() (url.Values, error)
+	// UnmarshalRequestBody parses the request body as JSON.
+	UnmarshalRequestBody(v interface{}) error
+	// UnmarshalRequestBodyWithOpts parses the request body as JSON with additional options for the decoding process.
+	//
+	// DisallowUnknownFields is often used with this method.
+	UnmarshalRequestBodyWithOpts(v interface{}, opts ...func(decoder *json.Decoder)) error
+
+	// StatusCode returns the current response's status code.
+	StatusCode() int
+	// SetStatusCode changes the current response's status code.
+	SetStatusCode(statusCode int)
+	// SetResponseHeader can be used to modify any response header.
+	SetResponseHeader(key, value string)
+	// SetCookie adds the cookie to the response.
+	//
+	// It is safe to call the method multiple times on the same cookie's name.
+	//
+	// Be sure to read the documentation of http.Cookie, especially on how you need to fill out more than just name and
+	// value for the [http.Cookie.String] to return a Set-Cookie response.
+	// Returns the response of [http.Cookie.Valid]; if the cookie is not valid, it will not be added.
+	SetCookie(cookie http.Cookie) error
+	// SetCacheControl is a convenient method to modify the Cache-Control response header.
+	SetCacheControl(directives ...cachecontrol.ResponseDirective)
+	// SetResponseCachingHeaders can be used to modify the "ETag" and "Last-Modified" response headers.
+	//
+	// If the value doesn't implement either HasETag and/or HasLastModified, false will be return.
+	SetResponseCachingHeaders(v interface{}) (set bool)
+	// RespondOKWithJSON sets the response body to the JSON-encoded content of the argument v.
+	//
+	// Upon successfully setting the new response body, the status code is set to http.StatusOK, "Content-Type" header
+	// to "application/json; charset=utf-8", and "Content-Length" header to the number of bytes of the JSON content. If
+	// the value implements HasETag and/or HasLastModified, their value are added to the response headers as well.
+	//
+	// Use this method if you want to return a generic JSON result with 200 status code.
+	// Use RespondWithJSON if you need to customise the response further (set status code, headers, etc.).
+	RespondOKWithJSON(v interface{}) (err error)
+	// RespondWithJSON is a variant of RespondOKWithJSON without further side effects.
+	//
+	// Use this method if you need to customise the response further (set status code, headers, etc.).
+	// Use RespondOKWithJSON if you want some sensible settings to accompany the body.
+	RespondWithJSON(v interface{}) (err error)
+	// RespondOKWithText sets the response body to the specified value.
+	//
+	// Upon successfully setting the new response body, the status code is set to http.StatusOK, "Content-Type" header
+	// to "text/plain; charset=utf-8", and "Content-Length" header to the length of the body which is the number of
+	// bytes, not the number of runes.
+	//
+	// Use this method if you want to return a generic plain-text result with 200 status code.
+	// Use RespondWithText if you need to customise the response further (set status code, headers, etc.).
+	RespondOKWithText(body string) (err error)
+	// RespondWithText is a variant of RespondOKWithText without further side effects.
+	//
+	// Use this method if you need to customise the response further (set status code, headers, etc.).
+	// Use RespondOKWithText if you want some sensible settings to accompany the body.
+	RespondWithText(body string) (err error)
+	// RespondOKWithBase64Data sets the response body to the base64 encoding of the given data.
+	//
+	// Upon successfully setting the new response body, the status code is set to http.StatusOK. You must still manually
+	// set "Content-Type" header.
+	RespondOKWithBase64Data(data []byte) (err error)
+	// RespondWithBase64Data is a variant of RespondOKWithBase64Data without effecting status code changes.
+	RespondWithBase64Data(data []byte) (err error)
+	// RespondOKWithBody sets the response body to the given [io.Reader].
+	//
+	// Useful if the handler is in STREAMING instead of BUFFERED mode. In BUFFERED mode, the reader will be read in full
+	// and passed to RespondWithBase64Data.
+	RespondOKWithBody(body io.Reader) (err error)
+	// RespondWithBody is a variant of RespondOKWithBody without effecting status code changes.
+	RespondWithBody(body io.Reader) (err error)
+	// SetResponseFormatterContentType changes the content type of the response generated by RespondFormatted.
+	SetResponseFormatterContentType(t ResponseFormatterContentType)
+	// RespondFormatted generates a response with the specified status code and formatted message.
+	//
+	// Upon successfully setting the new response body, the status code is also changed accordingly, and header
+	// "Content-Type" is set to match the type of response which is JSONResponse by default. The format can be changed with
+	// [Context.SetResponseFormatterContentType].
+	//
+	// The JSON response's body looks like this:
+	//
+	//	{ "status": statusCode, "message": sprintf(layout, v...) }
+	//
+	// The plain text response's body is the message.
+	//
+	// If you don't need a custom message, use RespondFormattedStatus which will use http.StatusText as the message.
+	RespondFormatted(statusCode int, layout string, v ...interface{}) error
+	// RespondFormattedStatus is a variant of RespondFormatted that derives the message from the status code.
+	//
+	// Equivalent to:
+	//
+	//	c.RespondFormatted(statusCode, "%s", http.StatusText(statusCode))
+	//
+	// Use this if the status code is sufficient, and you don't need a customised message.
+	RespondFormattedStatus(statusCode int) (err error)
+	// RespondInternalServerError calls RespondFormattedStatus with http.StatusInternalServerError.
+	RespondInternalServerError() error
+	// RespondBadRequest calls RespondFormatted passing http.StatusBadRequest and the message..
+	RespondBadRequest(layout string, v ...interface{}) error
+	// RespondNotFound calls RespondFormattedStatus with http.StatusNotFound.
+	RespondNotFound() error
+	// RespondMethodNotAllowed calls RespondFormattedStatus with http.StatusMethodNotAllowed and upon success also sets the
+	// "Allow" response header.
+	RespondMethodNotAllowed(allow string) (err error)
+
+	// ParseIfMatch parses the "If-Match" request header and returns the directives.
+	//
+	// If the request doesn't contain "If-Match" header, returns nil, nil.
+	ParseIfMatch() (*etag.Directives, error)
+	// ParseIfNoneMatch parses the "If-None-Match" request header and returns the directives.
+	//
+	// If the request doesn't contain "If-None-Match" header, returns nil, nil.
+	ParseIfNoneMatch() (*etag.Directives, error)
+	// ParseIfModifiedSince parses the "If-Modified-Since" request header and returns the time.Time.
+	//
+	// If the request doesn't contain "If-Modified-Since" header, returns zero-value time.Time, nil.
+	ParseIfModifiedSince() (time.Time, error)
+	// ParseIfUnmodifiedSince parses the "If-Unmodified-Since" request header and returns the directives.
+	//
+	// If the request doesn't contain "If-Unmodified-Since" header, returns zero-value time.Time, nil.
+	ParseIfUnmodifiedSince() (time.Time, error)
+
+	// EvaluatePreconditions evaluates the conditional request headers against the current representation v.
+	//
+	// The "ETag" and "Last-Modified" of v come from HasETag and HasLastModified. Pass nil if the resource doesn't exist
+	// so that "If-Match" fails and "If-None-Match: *" succeeds (e.g. for create-only PUT requests).
+	//
+	// The headers are evaluated in the order of RFC 9110 section 13.2.2: "If-Match" (strong comparison), or
+	// "If-Unmodified-Since" if there is no "If-Match"; then "If-None-Match" (weak comparison), or "If-Modified-Since" if
+	// there is no "If-None-Match" and the request is GET or HEAD. Invalid dates are ignored.
+	//
+	// Returns http.StatusNotModified for GET and HEAD requests whose "If-None-Match" or "If-Modified-Since" fails,
+	// http.StatusPreconditionFailed for any other failing precondition, or 0 if all preconditions pass. Returns a
+	// non-nil error if "If-Match" or "If-None-Match" cannot be parsed.
+	EvaluatePreconditions(v interface{}) (statusCode int, err error)
+	// CheckPreconditions is a variant of EvaluatePreconditions that responds on failures.
+	//
+	// If the preconditions fail, the response is set to RespondNotModified, RespondFormattedStatus with
+	// http.StatusPreconditionFailed, or RespondBadRequest if the headers are invalid, and false is returned along with
+	// the error from setting the response. Useful to guard state-changing requests:
+	//
+	//	if ok, err := c.CheckPreconditions(item); !ok {
+	//		return err
+	//	}
+	CheckPreconditions(v interface{}) (ok bool, err error)
+	// RespondNotModified sets the status code to http.StatusNotModified with an empty body.
+	//
+	// If the value implements HasETag and/or HasLastModified, their value are added to the response headers as well.
+	RespondNotModified(v interface{}) (err error)
+	// RespondConditionally calls CheckPreconditions, then RespondOKWithJSON if the preconditions pass.
+	//
+	// Use this method in place of RespondOKWithJSON to have conditional GET requests answered with 304 Not Modified.
+	RespondConditionally(v interface{}) (err error)
+	// EnableContentETag opts in to content-based ETag.
+	//
+	// After the handler returns successfully with http.StatusOK and no "ETag" header, a strong ETag is computed from the
+	// response body (see etag.Hasher) and added as the "ETag" header. If the request is GET or HEAD and its
+	// "If-None-Match" matches the ETag, the body is removed and the status code changed to http.StatusNotModified.
+	//
+	// In BUFFERED mode, the body is already in memory so maxSize is ignored. In RESPONSE_STREAM mode, the body is read
+	// into memory and hashed before it can be streamed; if the body is larger than maxSize bytes, it is streamed without
+	// an ETag. Pass 0 to use DefaultContentETagMaxSize.
+	EnableContentETag(maxSize int64)
+	// EnableCompression opts in to response compression.
+	//
+	// After the handler returns successfully with a status code that has a body (see compress.CompressibleStatus), no
+	// "Content-Encoding" header, and a compressible "Content-Type" (see compress.Compressible), "Accept-Encoding" is
+	// added to the "Vary" header and the encoding is negotiated with the "Accept-Encoding" request header (see
+	// compress.Negotiate). The encoders are given in order of preference; pass none to use compress.Gzip. Bodies smaller
+	// than minimumSize bytes are not compressed; pass 0 to use compress.DefaultMinimumSize. A strong "ETag" is made weak
+	// since the compressed body is a different representation.
+	//
+	// In BUFFERED mode, the body is compressed in full and the metrics have counters CounterKeyUncompressedSize and
+	// CounterKeyCompressedSize, and floater FloaterKeyCompressionRatio. In RESPONSE_STREAM mode, the body is compressed
+	// as it is streamed; because the request's metrics have been logged by then, the same metrics are logged by a child
+	// named "compress" (see metrics.Metrics.Child) once the body has been streamed in full.
+	EnableCompression(minimumSize int64, encoders ...compress.Encoder)
+
+	// RespondProblem sets the response body to the RFC 9457 problem details p with "Content-Type" set to
+	// ProblemContentType.
+	//
+	// The status code is changed to [Problem.Status], which defaults to http.StatusBadRequest if 0; [Problem.Title]
+	// defaults to the http.StatusText of the status code.
+	RespondProblem(p Problem) (err error)
+	// ValidateRequestBody parses the request body as JSON like UnmarshalRequestBodyWithOpts, then validates v against
+	// its "validate" struct tags (see validate.Struct).
+	//
+	// If the body cannot be decoded, or one or more fields fail their rules, the response is set to RespondProblem with
+	// http.StatusBadRequest, listing every field error in [Problem.Errors], and false is returned along with the error
+	// from setting the response. A malformed struct tag is a programming error; it is returned as is after setting the
+	// response to RespondInternalServerError. Use it in place of UnmarshalRequestBody:
+	//
+	//	var item Item
+	//	if ok, err := c.ValidateRequestBody(&item, httpcontext.DisallowUnknownFields); !ok {
+	//		return err
+	//	}
+	ValidateRequestBody(v interface{}, opts ...func(decoder *json.Decoder)) (ok bool, err error)
+}
+
+// DisallowUnknownFields is to be used with UnmarshalRequestBodyWithOpts to disallow unknown fields in decoded JSON.
+func DisallowUnknownFields(dec *json.Decoder) {
+	dec.DisallowUnknownFields()
+}
+
+// ResponseFormatterContentType describes which format [Context.RespondFormatted] use which is JSONResponse by default.
+type ResponseFormatterContentType int
+
+const (
+	JSONResponse ResponseFormatterContentType = iota
+	TextResponse
+)
+
+// HasETag allows [Context.RespondOKWithJSON] to add "ETag" header to the response.
+type HasETag interface {
+	GetETag() etag.ETag
+}
+
+// HasLastModified allows [Context.RespondOKWithJSON] to add "Last-Modified" header to the response.
+type HasLastModified interface {
+	GetLastModified() time.Time
+}
+
+// ProblemContentType is the "Content-Type" of the response generated by [Context.RespondProblem].
+const ProblemContentType = "application/problem+json"
+
+// Problem is the problem details object of RFC 9457.
+//
+// All members are optional; an empty Type is equivalent to "about:blank", in which case Title should be the
+// http.StatusText of Status.
+type Problem struct {
+	// Type is a URI reference that identifies the problem type.
+	Type string `json:"type,omitempty"`
+	// Title is a short, human-readable summary of the problem type.
+	Title string `json:"title,omitempty"`
+	// Status is the HTTP status code.
+	Status int `json:"status,omitempty"`
+	// Detail is a human-readable explanation specific to this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+	// Instance is a URI reference that identifies the specific occurrence of the problem.
+	Instance string `json:"instance,omitempty"`
+	// Errors is an extension member that lists the fields of the request body that fail validation.
+	Errors validate.Errors `json:"errors,omitempty"`
+}
+
+// Adapt converts a handler of Context into a handler of the more specific context C, such as lambdafunctionurl.Context
+// or *framework.Context, so that the same handler can be passed to either StartWrapper or Start.
+func Adapt[C Context](handler func(Context) error) func(C) error {
+	return func(c C) error {
+		return handler(c)
+	}
+}
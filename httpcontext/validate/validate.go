@@ -0,0 +1,424 @@
+// Package validate checks the fields of a decoded request body against rules given as struct tags.
+//
+// The rules are given by the "validate" struct tag as a comma-separated list:
+//
+//	type Item struct {
+//		Name     string   `json:"name" validate:"required,max=64"`
+//		Quantity int      `json:"quantity" validate:"min=1,max=100"`
+//		Status   *string  `json:"status" validate:"enum=active|archived"`
+//		SKU      string   `json:"sku" validate:"regex=^[A-Z]{3}-[0-9]+$"`
+//		Tags     []string `json:"tags" validate:"max=10"`
+//		Address  *Address `json:"address" validate:"required"`
+//	}
+//
+// The supported rules are:
+//   - required: the value must not be empty, i.e. nil pointers, empty strings, empty slices and maps, 0, and false all
+//     fail. A non-nil pointer passes even if it points to a zero value, so use a pointer if 0 or false is valid.
+//   - min=n and max=n: numbers must be within the bounds; strings must have between min and max characters (runes),
+//     and slices and maps must have between min and max elements.
+//   - enum=a|b|c: the value formatted with fmt.Sprint must be one of the pipe-separated values.
+//   - regex=pattern: strings must match the pattern. Because the pattern may contain commas, regex must be the last rule.
+//
+// All rules other than required are skipped if the value is absent, i.e. a nil pointer, interface, slice, or map, so
+// combine them with required if such a field must be present. Other values are always checked: a Quantity of 0 fails
+// min=1, and so does an empty (but non-nil) slice, so use a pointer for an optional number or string. If required fails,
+// the field's other rules are not checked. Nested structs, pointers to structs, and slices and maps of structs are validated recursively. Fields are
+// named after their "json" struct tag so that the errors match the request body.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// FieldError describes a field that fails one of its rules.
+type FieldError struct {
+	// Field is the dotted path to the field, e.g. "items[0].name".
+	Field string `json:"field"`
+	// Pointer is the JSON Pointer (RFC 6901) to the field as a URI fragment, e.g. "#/items/0/name".
+	Pointer string `json:"pointer"`
+	// Rule is the rule that fails, e.g. "required" or "max".
+	Rule string `json:"rule"`
+	// Detail is a human-readable explanation, e.g. "must be at most 64 characters".
+	Detail string `json:"detail"`
+}
+
+// Error implements the error interface.
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Detail
+}
+
+// Errors is returned by Struct if one or more fields fail their rules.
+type Errors []FieldError
+
+// Error implements the error interface.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Struct validates the fields of the struct (or pointer to struct) v against their "validate" struct tags.
+//
+// Returns Errors listing every field that fails its rules, or nil if all fields pass. Returns a different error if a
+// struct tag is malformed, such as an invalid regex or a min on a bool field.
+func Struct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: expected struct, got %s", rv.Kind())
+	}
+
+	var errs Errors
+	if err := validateStruct(rv, path{}, &errs); err != nil {
+		return err
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// path tracks the dotted and JSON Pointer paths of the current field.
+type path struct {
+	field   string
+	pointer string
+}
+
+func (p path) key(name string) path {
+	field := name
+	if p.field != "" {
+		field = p.field + "." + name
+	}
+	return path{field: field, pointer: p.pointer + "/" + escapePointer(name)}
+}
+
+func (p path) index(i string) path {
+	return path{field: p.field + "[" + i + "]", pointer: p.pointer + "/" + escapePointer(i)}
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func validateStruct(rv reflect.Value, p path, errs *Errors) error {
+	fields, err := cachedFields(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		fv := rv.FieldByIndex(f.index)
+		fp := p
+		if !f.inline {
+			fp = p.key(f.name)
+		}
+
+		if err = validateValue(fv, f.rules, fp, errs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateValue(v reflect.Value, rules []rule, p path, errs *Errors) error {
+	for _, r := range rules {
+		target := v
+		if r.name != "required" {
+			if isAbsent(v) {
+				continue
+			}
+			target = indirect(v)
+		}
+		if detail := r.check(target); detail != "" {
+			*errs = append(*errs, FieldError{Field: p.field, Pointer: "#" + p.pointer, Rule: r.name, Detail: detail})
+
+			// a missing value would fail the other rules too, which only adds noise.
+			if r.name == "required" {
+				break
+			}
+		}
+	}
+
+	return validateNested(indirect(v), p, errs)
+}
+
+func validateNested(v reflect.Value, p path, errs *Errors) error {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return validateStruct(v, p, errs)
+	case reflect.Slice, reflect.Array:
+		if !hasStruct(v.Type().Elem()) {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := validateNested(indirect(v.Index(i)), p.index(strconv.Itoa(i)), errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if !hasStruct(v.Type().Elem()) {
+			return nil
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+		for _, k := range keys {
+			if err := validateNested(indirect(v.MapIndex(k)), p.key(fmt.Sprint(k.Interface())), errs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func hasStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isAbsent returns true for nil pointers, interfaces, slices, and maps, i.e. fields that are missing from the request
+// body or explicitly null.
+func isAbsent(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	}
+	return false
+}
+
+// isEmpty returns true for nil pointers and zero values; a pointer to a zero value is not empty.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// field is a struct field that has rules or may contain nested structs.
+type field struct {
+	index  []int
+	name   string
+	inline bool
+	rules  []rule
+}
+
+var cache sync.Map // map[reflect.Type][]field
+
+func cachedFields(t reflect.Type) ([]field, error) {
+	if fields, ok := cache.Load(t); ok {
+		return fields.([]field), nil
+	}
+
+	fields := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		tag := sf.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+
+		rules, err := parseRules(tag, sf.Type)
+		if err != nil {
+			return nil, fmt.Errorf("validate: field %s.%s: %w", t.Name(), sf.Name, err)
+		}
+
+		f := field{index: sf.Index, name: name, rules: rules}
+		if name == "" {
+			// embedded structs without a json name are flattened like encoding/json does.
+			f.name = sf.Name
+			f.inline = sf.Anonymous && hasStruct(sf.Type)
+		}
+		if len(f.rules) == 0 && !hasStruct(elem(sf.Type)) {
+			continue
+		}
+
+		fields = append(fields, f)
+	}
+
+	cache.Store(t, fields)
+	return fields, nil
+}
+
+// elem returns the type of the elements of slices, arrays, and maps, or the type itself.
+func elem(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return t.Elem()
+	}
+	return t
+}
+
+// rule checks a value that is not absent, returning a non-empty detail if the value fails the rule.
+type rule struct {
+	name  string
+	check func(v reflect.Value) string
+}
+
+var errUnsupportedKind = errors.New("unsupported kind")
+
+func parseRules(tag string, t reflect.Type) ([]rule, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var rules []rule
+	for tag != "" {
+		var spec string
+		if strings.HasPrefix(tag, "regex=") {
+			spec, tag = tag, ""
+		} else {
+			spec, tag, _ = strings.Cut(tag, ",")
+		}
+
+		name, arg, _ := strings.Cut(strings.TrimSpace(spec), "=")
+		r, err := newRule(name, arg, t)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", spec, err)
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+func newRule(name, arg string, t reflect.Type) (rule, error) {
+	switch name {
+	case "required":
+		return rule{name: name, check: func(v reflect.Value) string {
+			if isEmpty(v) {
+				return "is required"
+			}
+			return ""
+		}}, nil
+
+	case "min", "max":
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return rule{}, err
+		}
+		return boundRule(name, bound, t)
+
+	case "enum":
+		values := strings.Split(arg, "|")
+		detail := "must be one of " + strings.Join(values, ", ")
+		return rule{name: name, check: func(v reflect.Value) string {
+			if slices.Contains(values, fmt.Sprint(v.Interface())) {
+				return ""
+			}
+			return detail
+		}}, nil
+
+	case "regex":
+		if t.Kind() != reflect.String {
+			return rule{}, errUnsupportedKind
+		}
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return rule{}, err
+		}
+		detail := "must match " + arg
+		return rule{name: name, check: func(v reflect.Value) string {
+			if re.MatchString(v.String()) {
+				return ""
+			}
+			return detail
+		}}, nil
+	}
+
+	return rule{}, fmt.Errorf("unknown rule")
+}
+
+func boundRule(name string, bound float64, t reflect.Type) (rule, error) {
+	var (
+		size func(v reflect.Value) float64
+		unit string
+	)
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = func(v reflect.Value) float64 { return float64(v.Int()) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = func(v reflect.Value) float64 { return float64(v.Uint()) }
+	case reflect.Float32, reflect.Float64:
+		size = func(v reflect.Value) float64 { return v.Float() }
+	case reflect.String:
+		size = func(v reflect.Value) float64 { return float64(utf8.RuneCountInString(v.String())) }
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		size = func(v reflect.Value) float64 { return float64(v.Len()) }
+		unit = " elements"
+	default:
+		return rule{}, errUnsupportedKind
+	}
+
+	s := strconv.FormatFloat(bound, 'f', -1, 64)
+	if name == "min" {
+		detail := "must be at least " + s + unit
+		return rule{name: name, check: func(v reflect.Value) string {
+			if size(v) < bound {
+				return detail
+			}
+			return ""
+		}}, nil
+	}
+
+	detail := "must be at most " + s + unit
+	return rule{name: name, check: func(v reflect.Value) string {
+		if size(v) > bound {
+			return detail
+		}
+		return ""
+	}}, nil
+}
@@ -0,0 +1,167 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type address struct {
+	Zip string `json:"zip" validate:"required,regex=^[0-9]{5}$"`
+}
+
+type item struct {
+	Name     string         `json:"name" validate:"required,max=5"`
+	Quantity int            `json:"quantity" validate:"min=1,max=10"`
+	Price    *float64       `json:"price" validate:"required,min=0.5"`
+	Status   string         `json:"status,omitempty" validate:"enum=active|archived"`
+	Tags     []string       `json:"tags" validate:"max=2"`
+	Address  *address       `json:"address" validate:"required"`
+	Lines    []address      `json:"lines"`
+	ByName   map[string]any `json:"byName"`
+	Ignored  string         `json:"-" validate:"required"`
+	internal string
+}
+
+func TestStruct(t *testing.T) {
+	price, one := 0.25, 1.0
+	tests := []struct {
+		name string
+		v    interface{}
+		want Errors
+	}{
+		{
+			name: "valid",
+			v: &item{
+				Name:     "apple",
+				Quantity: 3,
+				Price:    &one,
+				Status:   "active",
+				Address:  &address{Zip: "12345"},
+				Lines:    []address{{Zip: "54321"}},
+			},
+		},
+		{
+			name: "missing",
+			v:    item{},
+			want: Errors{
+				{Field: "name", Pointer: "#/name", Rule: "required", Detail: "is required"},
+				{Field: "quantity", Pointer: "#/quantity", Rule: "min", Detail: "must be at least 1"},
+				{Field: "price", Pointer: "#/price", Rule: "required", Detail: "is required"},
+				{Field: "status", Pointer: "#/status", Rule: "enum", Detail: "must be one of active, archived"},
+				{Field: "address", Pointer: "#/address", Rule: "required", Detail: "is required"},
+			},
+		},
+		{
+			name: "invalid",
+			v: item{
+				Name:     "bananas",
+				Quantity: 11,
+				Price:    &price,
+				Status:   "deleted",
+				Tags:     []string{"a", "b", "c"},
+				Address:  &address{},
+				Lines:    []address{{Zip: "12345"}, {Zip: "abc"}},
+			},
+			want: Errors{
+				{Field: "name", Pointer: "#/name", Rule: "max", Detail: "must be at most 5 characters"},
+				{Field: "quantity", Pointer: "#/quantity", Rule: "max", Detail: "must be at most 10"},
+				{Field: "price", Pointer: "#/price", Rule: "min", Detail: "must be at least 0.5"},
+				{Field: "status", Pointer: "#/status", Rule: "enum", Detail: "must be one of active, archived"},
+				{Field: "tags", Pointer: "#/tags", Rule: "max", Detail: "must be at most 2 elements"},
+				{Field: "address.zip", Pointer: "#/address/zip", Rule: "required", Detail: "is required"},
+				{Field: "lines[1].zip", Pointer: "#/lines/1/zip", Rule: "regex", Detail: "must match ^[0-9]{5}$"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Struct(tt.v)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Struct() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var got Errors
+			if !errors.As(err, &got) {
+				t.Fatalf("Struct() error = %v, want Errors", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Struct() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStruct_zeroValues(t *testing.T) {
+	type order struct {
+		Quantity int      `json:"quantity" validate:"min=1"`
+		Tags     []string `json:"tags" validate:"min=1"`
+		Note     *string  `json:"note" validate:"min=1"`
+	}
+
+	var got Errors
+	if err := Struct(order{Tags: []string{}}); !errors.As(err, &got) {
+		t.Fatalf("Struct() error = %v, want Errors", err)
+	}
+	if want := (Errors{
+		{Field: "quantity", Pointer: "#/quantity", Rule: "min", Detail: "must be at least 1"},
+		{Field: "tags", Pointer: "#/tags", Rule: "min", Detail: "must be at least 1 elements"},
+	}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Struct() got = %#v, want %#v", got, want)
+	}
+
+	// absent values skip every rule other than required.
+	if err := Struct(order{Quantity: 1}); err != nil {
+		t.Errorf("Struct() error = %v, want nil", err)
+	}
+}
+
+func TestStruct_embedded(t *testing.T) {
+	type Base struct {
+		ID string `json:"id" validate:"required"`
+	}
+	type withBase struct {
+		Base
+		Name string `json:"name" validate:"required"`
+	}
+
+	var got Errors
+	if err := Struct(withBase{Name: "test"}); !errors.As(err, &got) {
+		t.Fatalf("Struct() error = %v, want Errors", err)
+	}
+	if want := (Errors{{Field: "id", Pointer: "#/id", Rule: "required", Detail: "is required"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Struct() got = %#v, want %#v", got, want)
+	}
+}
+
+func TestStruct_malformedTag(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "unknown rule", v: struct {
+			A string `validate:"email"`
+		}{}},
+		{name: "invalid bound", v: struct {
+			A int `validate:"min=abc"`
+		}{}},
+		{name: "unsupported kind", v: struct {
+			A bool `validate:"max=1"`
+		}{}},
+		{name: "invalid regex", v: struct {
+			A string `validate:"regex=("`
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Struct(tt.v)
+			var errs Errors
+			if err == nil || errors.As(err, &errs) {
+				t.Errorf("Struct() error = %v, want malformed tag error", err)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/lambdafunctionurl/compress"
 	"github.com/nguyengg/golambda/lambdafunctionurl/etag"
 	"github.com/nguyengg/golambda/metrics"
 	"net/http"
@@ -22,6 +23,10 @@ type baseContext[T any] struct {
 	requestCookies               map[string]string
 	response                     Response[T]
 	responseFormatterContentType ResponseFormatterContentType
+	contentETag                  bool
+	contentETagMaxSize           int64
+	compressMinimumSize          int64
+	compressEncoders             []compress.Encoder
 }
 
 func newContext[T any](ctx context.Context, request *events.LambdaFunctionURLRequest, response Response[T]) *baseContext[T] {
@@ -4,9 +4,12 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/lambdafunctionurl/compress"
+	"github.com/nguyengg/golambda/lambdafunctionurl/etag"
 	"io"
 	"net/http"
 	"net/textproto"
+	"strconv"
 	"strings"
 )
 
@@ -79,3 +82,61 @@ func (r *Response) RespondBody(body io.Reader) error {
 func (r *Response) Response() *events.LambdaFunctionURLResponse {
 	return r.response
 }
+
+// Header returns the value of the response header with the given key.
+func (r *Response) Header(key string) string {
+	return r.response.Headers[textproto.CanonicalMIMEHeaderKey(key)]
+}
+
+// ContentETag returns the strong ETag computed from the response body.
+//
+// The maxSize argument is ignored since the body is already in memory; ok is always true unless the body cannot be
+// decoded.
+func (r *Response) ContentETag(maxSize int64) (e etag.ETag, ok bool, err error) {
+	data, err := r.body()
+	if err != nil {
+		return e, false, err
+	}
+
+	return etag.FromContent(data), true, nil
+}
+
+// Compress replaces the response body with its compressed content and updates the "Content-Length" header.
+//
+// If the body is smaller than minimumSize bytes, it is left as is and ok is false. Otherwise, done is called with the
+// number of uncompressed and compressed bytes before Compress returns.
+func (r *Response) Compress(enc compress.Encoder, minimumSize int64, done func(uncompressedSize, compressedSize int64)) (ok bool, err error) {
+	data, err := r.body()
+	if err != nil || int64(len(data)) < minimumSize {
+		return false, err
+	}
+
+	compressed, err := compress.Bytes(enc, data)
+	if err != nil {
+		return false, err
+	}
+
+	_ = r.RespondBase64Data(compressed)
+	r.SetHeader("Content-Length", strconv.Itoa(len(compressed)))
+	if done != nil {
+		done(int64(len(data)), int64(len(compressed)))
+	}
+
+	return true, nil
+}
+
+// body returns the decoded response body.
+func (r *Response) body() ([]byte, error) {
+	if !r.response.IsBase64Encoded {
+		return []byte(r.response.Body), nil
+	}
+
+	return base64.StdEncoding.DecodeString(r.response.Body)
+}
+
+// ClearBody removes the response body and the "Content-Length" header.
+func (r *Response) ClearBody() {
+	r.response.Body = ""
+	r.response.IsBase64Encoded = false
+	delete(r.response.Headers, "Content-Length")
+}
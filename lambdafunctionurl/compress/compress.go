@@ -0,0 +1,167 @@
+// Package compress negotiates the content-coding of a response from the "Accept-Encoding" request header.
+//
+// Gzip and Deflate are implemented with the standard library. Other codings such as Brotli and Zstandard can be added
+// by wrapping third-party implementations as an Encoder, for example with github.com/andybalholm/brotli and
+// github.com/klauspost/compress/zstd:
+//
+//	br := compress.Encoder{Coding: "br", NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+//		return brotli.NewWriter(w), nil
+//	}}
+//	zstd := compress.Encoder{Coding: "zstd", NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+//		return zstd.NewWriter(w)
+//	}}
+//
+//	c.EnableCompression(0, br, zstd, compress.Gzip)
+package compress
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// DefaultMinimumSize is the default minimum size in bytes of a response body to be compressed.
+//
+// Smaller bodies don't benefit from compression enough to warrant the overhead.
+const DefaultMinimumSize = 1024
+
+// Encoder compresses data with a specific content-coding.
+type Encoder struct {
+	// Coding is the content-coding such as "gzip" that is matched against the "Accept-Encoding" request header and
+	// returned as the "Content-Encoding" response header.
+	Coding string
+	// NewWriter returns a writer that compresses everything written to it into w.
+	//
+	// Close must flush the remaining data but must not close w.
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+}
+
+// Gzip is the Encoder for "gzip" using compress/gzip with default compression.
+var Gzip = Encoder{
+	Coding: "gzip",
+	NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	},
+}
+
+// Deflate is the Encoder for "deflate" using compress/zlib with default compression.
+//
+// Despite its name, the "deflate" content-coding is the zlib format. See
+// https://www.rfc-editor.org/rfc/rfc9110#section-8.4.1.2.
+var Deflate = Encoder{
+	Coding: "deflate",
+	NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+		return zlib.NewWriter(w), nil
+	},
+}
+
+// Coding is a content-coding and its quality value from the "Accept-Encoding" request header.
+type Coding struct {
+	Name string
+	Q    float64
+}
+
+// ParseAcceptEncoding parses the "Accept-Encoding" request header.
+//
+// Names are lower-cased, and "x-gzip" is normalised to "gzip". A missing or invalid quality value defaults to 1.
+// Returns the codings in the order of the header.
+func ParseAcceptEncoding(value string) []Coding {
+	var codings []Coding
+	for _, v := range strings.Split(value, ",") {
+		name, params, _ := strings.Cut(v, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if name == "x-gzip" {
+			name = "gzip"
+		}
+
+		coding := Coding{Name: name, Q: 1}
+		for _, p := range strings.Split(params, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if strings.EqualFold(k, "q") {
+				if q, err := strconv.ParseFloat(v, 64); err == nil && q >= 0 && q <= 1 {
+					coding.Q = q
+				}
+			}
+		}
+
+		codings = append(codings, coding)
+	}
+
+	return codings
+}
+
+// Negotiate picks the Encoder to use given the "Accept-Encoding" request header.
+//
+// The encoders are given in the server's order of preference. The Encoder whose coding has the highest quality value
+// is picked, with ties broken by the server's preference. A coding that is not listed gets the quality value of "*"
+// if present. Codings with a quality value of 0 are never picked. Returns false if no Encoder is acceptable, in which
+// case the response should not be compressed. An empty header also returns false since clients that don't send the
+// header rarely expect a compressed response.
+func Negotiate(acceptEncoding string, encoders ...Encoder) (Encoder, bool) {
+	codings := ParseAcceptEncoding(acceptEncoding)
+
+	var (
+		best  Encoder
+		bestQ float64
+	)
+	for _, e := range encoders {
+		q, wildcard := -1.0, -1.0
+		for _, c := range codings {
+			switch c.Name {
+			case e.Coding:
+				q = c.Q
+			case "*":
+				wildcard = c.Q
+			}
+		}
+		if q < 0 {
+			q = wildcard
+		}
+
+		if q > bestQ {
+			best, bestQ = e, q
+		}
+	}
+
+	return best, bestQ > 0
+}
+
+// Compressible returns true if the "Content-Type" is a text format that benefits from compression.
+//
+// Types that are already compressed such as images (except SVG), audio, video, and archives are not compressible.
+func Compressible(contentType string) bool {
+	t, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	if strings.HasPrefix(t, "text/") || strings.HasSuffix(t, "+json") || strings.HasSuffix(t, "+xml") {
+		return true
+	}
+
+	switch t {
+	case "application/json", "application/javascript", "application/xml", "application/x-www-form-urlencoded",
+		"application/x-ndjson", "image/svg+xml":
+		return true
+	}
+
+	return false
+}
+
+// CompressibleStatus returns true if a response with the given status code has a body that may be compressed.
+//
+// 206 Partial Content is excluded because its "Content-Range" refers to the uncompressed representation.
+func CompressibleStatus(statusCode int) bool {
+	switch {
+	case statusCode < 200, statusCode == 204, statusCode == 206, statusCode == 304:
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,135 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAcceptEncoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []Coding
+	}{
+		{name: "empty", value: ""},
+		{
+			name:  "no q",
+			value: "gzip, deflate, br",
+			want:  []Coding{{"gzip", 1}, {"deflate", 1}, {"br", 1}},
+		},
+		{
+			name:  "q values",
+			value: "br;q=1.0, GZIP; q=0.5, *;q=0",
+			want:  []Coding{{"br", 1}, {"gzip", 0.5}, {"*", 0}},
+		},
+		{
+			name:  "x-gzip and invalid q",
+			value: "x-gzip;q=2, identity;q=abc",
+			want:  []Coding{{"gzip", 1}, {"identity", 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseAcceptEncoding(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAcceptEncoding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	br := Encoder{Coding: "br"}
+
+	tests := []struct {
+		name     string
+		value    string
+		encoders []Encoder
+		want     string
+		wantOk   bool
+	}{
+		{name: "empty", value: "", encoders: []Encoder{Gzip}},
+		{name: "exact", value: "gzip", encoders: []Encoder{br, Gzip}, want: "gzip", wantOk: true},
+		{name: "server preference on tie", value: "gzip, br", encoders: []Encoder{br, Gzip}, want: "br", wantOk: true},
+		{name: "client q wins", value: "br;q=0.5, gzip", encoders: []Encoder{br, Gzip}, want: "gzip", wantOk: true},
+		{name: "wildcard", value: "*", encoders: []Encoder{Deflate, Gzip}, want: "deflate", wantOk: true},
+		{name: "wildcard excludes", value: "gzip;q=0, *", encoders: []Encoder{Gzip, Deflate}, want: "deflate", wantOk: true},
+		{name: "not acceptable", value: "identity", encoders: []Encoder{Gzip}},
+		{name: "zero q", value: "gzip;q=0", encoders: []Encoder{Gzip}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Negotiate(tt.value, tt.encoders...)
+			if ok != tt.wantOk || got.Coding != tt.want {
+				t.Errorf("Negotiate() = (%q, %t), want (%q, %t)", got.Coding, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestCompressible(t *testing.T) {
+	tests := map[string]bool{
+		"text/html; charset=utf-8": true,
+		"application/json":         true,
+		"application/problem+json": true,
+		"image/svg+xml":            true,
+		"image/png":                false,
+		"application/zip":          false,
+		"application/octet-stream": false,
+		"":                         false,
+	}
+	for contentType, want := range tests {
+		if got := Compressible(contentType); got != want {
+			t.Errorf("Compressible(%q) = %t, want %t", contentType, got, want)
+		}
+	}
+}
+
+type closer struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestNewReader(t *testing.T) {
+	content := strings.Repeat("hello, world! ", 10000)
+	src := &closer{Reader: strings.NewReader(content)}
+
+	var in, out int64
+	r, err := NewReader(src, Gzip, func(uncompressedSize, compressedSize int64) {
+		in, out = uncompressedSize, compressedSize
+	})
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	compressed, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if err = r.Close(); err != nil || !src.closed {
+		t.Errorf("Close() error = %v, closed = %t", err, src.closed)
+	}
+	if in != int64(len(content)) || out != int64(len(compressed)) {
+		t.Errorf("done(%d, %d), want (%d, %d)", in, out, len(content), len(compressed))
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	data, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("read gzip error = %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("decompressed content mismatch; got %d bytes, want %d", len(data), len(content))
+	}
+}
@@ -0,0 +1,118 @@
+package compress
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// Bytes compresses data in full with the given Encoder.
+func Bytes(enc Encoder, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, err := enc.NewWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// NewReader returns a reader that compresses the content of src with the given Encoder as it is being read.
+//
+// The compression happens on the reader's goroutine without any additional buffering other than the compressed
+// output of each read from src, so the reader can be used to stream a response body of any size. If src implements
+// io.Closer, closing the returned reader also closes src.
+//
+// Because the sizes are only known once src has been read in full, done (if not nil) is called once with the number
+// of uncompressed and compressed bytes when the returned reader reaches io.EOF.
+func NewReader(src io.Reader, enc Encoder, done func(uncompressedSize, compressedSize int64)) (io.ReadCloser, error) {
+	r := &reader{src: src, done: done}
+
+	w, err := enc.NewWriter(&r.buf)
+	if err != nil {
+		return nil, err
+	}
+
+	r.w = w
+	return r, nil
+}
+
+type reader struct {
+	src  io.Reader
+	w    io.WriteCloser
+	buf  bytes.Buffer
+	p    []byte
+	eof  bool
+	err  error
+	in   int64
+	out  int64
+	done func(uncompressedSize, compressedSize int64)
+}
+
+func (r *reader) Read(p []byte) (n int, err error) {
+	for r.buf.Len() == 0 && !r.eof {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		r.fill()
+	}
+
+	if r.buf.Len() == 0 {
+		if r.done != nil {
+			r.done(r.in, r.out)
+			r.done = nil
+		}
+		return 0, io.EOF
+	}
+
+	n, _ = r.buf.Read(p)
+	r.out += int64(n)
+	return n, nil
+}
+
+// fill reads the next chunk from src and compresses it into buf.
+func (r *reader) fill() {
+	if r.p == nil {
+		r.p = make([]byte, 32*1024)
+	}
+
+	n, err := r.src.Read(r.p)
+	if n > 0 {
+		r.in += int64(n)
+		if _, werr := r.w.Write(r.p[:n]); werr != nil {
+			r.err = werr
+			return
+		}
+	}
+
+	switch {
+	case err == io.EOF:
+		r.eof = true
+		r.err = r.w.Close()
+		if r.err != nil {
+			r.eof = false
+		}
+	case err != nil:
+		r.err = err
+	}
+}
+
+func (r *reader) Close() error {
+	var err error
+	if !r.eof {
+		// release the resources of the encoder; its output is no longer needed.
+		err = r.w.Close()
+	}
+	if closer, ok := r.src.(io.Closer); ok {
+		err = errors.Join(err, closer.Close())
+	}
+	return err
+}
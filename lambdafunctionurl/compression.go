@@ -0,0 +1,107 @@
+package lambdafunctionurl
+
+import (
+	"github.com/nguyengg/golambda/httpcontext"
+	"github.com/nguyengg/golambda/lambdafunctionurl/compress"
+	"github.com/nguyengg/golambda/metrics"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Metrics emitted when a response is compressed; aliases of the httpcontext constants of the same names.
+const (
+	PropertyKeyContentEncoding = httpcontext.PropertyKeyContentEncoding
+	CounterKeyUncompressedSize = httpcontext.CounterKeyUncompressedSize
+	CounterKeyCompressedSize   = httpcontext.CounterKeyCompressedSize
+	FloaterKeyCompressionRatio = httpcontext.FloaterKeyCompressionRatio
+)
+
+// compressor is implemented by buffered.Response and streaming.Response to support response compression.
+type compressor interface {
+	Header(key string) string
+	Compress(enc compress.Encoder, minimumSize int64, done func(uncompressedSize, compressedSize int64)) (ok bool, err error)
+}
+
+func (c *baseContext[T]) EnableCompression(minimumSize int64, encoders ...compress.Encoder) {
+	if minimumSize <= 0 {
+		minimumSize = compress.DefaultMinimumSize
+	}
+	if len(encoders) == 0 {
+		encoders = []compress.Encoder{compress.Gzip}
+	}
+
+	c.compressMinimumSize = minimumSize
+	c.compressEncoders = encoders
+}
+
+// applyCompression is called after the handler has returned successfully.
+func (c *baseContext[T]) applyCompression() error {
+	if c.compressEncoders == nil || !compress.CompressibleStatus(c.StatusCode()) || c.RequestMethod() == http.MethodHead {
+		return nil
+	}
+
+	h, ok := c.response.(compressor)
+	if !ok || h.Header("Content-Encoding") != "" || !compress.Compressible(h.Header("Content-Type")) {
+		return nil
+	}
+
+	// the response varies by Accept-Encoding even if it ends up not being compressed.
+	addVary(c, h.Header("Vary"), "Accept-Encoding")
+
+	enc, ok := compress.Negotiate(c.RequestHeader("Accept-Encoding"), c.compressEncoders...)
+	if !ok {
+		return nil
+	}
+
+	// in BUFFERED mode, done is called before Compress returns so the sizes are added to the request's metrics. In
+	// RESPONSE_STREAM mode, the request's metrics have been logged by the time the body is streamed in full, so the
+	// sizes are logged with a child instead.
+	m := c.Metrics()
+	var streamed atomic.Bool
+	ok, err := h.Compress(enc, c.compressMinimumSize, func(uncompressedSize, compressedSize int64) {
+		if !streamed.Load() {
+			addCompressionMetrics(m, uncompressedSize, compressedSize)
+			return
+		}
+
+		child := m.Child("compress")
+		addCompressionMetrics(child, uncompressedSize, compressedSize)
+		child.Log()
+	})
+	streamed.Store(true)
+	if err != nil || !ok {
+		return err
+	}
+
+	c.SetResponseHeader("Content-Encoding", enc.Coding)
+	m.SetProperty(PropertyKeyContentEncoding, enc.Coding)
+
+	// the compressed representation is no longer byte-for-byte identical to the original.
+	if v := h.Header("ETag"); v != "" && !strings.HasPrefix(v, "W/") {
+		c.SetResponseHeader("ETag", "W/"+v)
+	}
+
+	return nil
+}
+
+func addCompressionMetrics(m metrics.Metrics, uncompressedSize, compressedSize int64) {
+	m.AddCount(CounterKeyUncompressedSize, uncompressedSize).AddCount(CounterKeyCompressedSize, compressedSize)
+	if compressedSize > 0 {
+		m.SetFloat(FloaterKeyCompressionRatio, float64(uncompressedSize)/float64(compressedSize))
+	}
+}
+
+// addVary adds the value to the "Vary" response header if it isn't already there.
+func addVary[T any](c *baseContext[T], vary, value string) {
+	for _, v := range strings.Split(vary, ",") {
+		if v = strings.TrimSpace(v); v == "*" || strings.EqualFold(v, value) {
+			return
+		}
+	}
+
+	if vary != "" {
+		value = vary + ", " + value
+	}
+	c.SetResponseHeader("Vary", value)
+}
@@ -0,0 +1,159 @@
+package lambdafunctionurl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/lambdafunctionurl/compress"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestContext_EnableCompression(t *testing.T) {
+	large := strings.Repeat("hello, world! ", 200)
+
+	tests := []struct {
+		name           string
+		method         string
+		acceptEncoding string
+		respond        func(c Context) error
+		wantEncoding   string
+		wantVary       string
+		wantETag       string
+	}{
+		{
+			name:           "gzip",
+			method:         "GET",
+			acceptEncoding: "deflate;q=0.5, gzip",
+			respond:        func(c Context) error { return c.RespondOKWithText(large) },
+			wantEncoding:   "gzip",
+			wantVary:       "Accept-Encoding",
+		},
+		{
+			name:           "weak etag",
+			method:         "GET",
+			acceptEncoding: "gzip",
+			respond: func(c Context) error {
+				c.SetResponseHeader("ETag", `"v1"`)
+				c.SetResponseHeader("Vary", "Origin")
+				return c.RespondOKWithText(large)
+			},
+			wantEncoding: "gzip",
+			wantVary:     "Origin, Accept-Encoding",
+			wantETag:     `W/"v1"`,
+		},
+		{
+			name:           "not accepted",
+			method:         "GET",
+			acceptEncoding: "gzip;q=0",
+			respond:        func(c Context) error { return c.RespondOKWithText(large) },
+			wantVary:       "Accept-Encoding",
+		},
+		{
+			name:           "too small",
+			method:         "GET",
+			acceptEncoding: "gzip",
+			respond:        func(c Context) error { return c.RespondOKWithText("hello") },
+			wantVary:       "Accept-Encoding",
+		},
+		{
+			name:           "not compressible",
+			method:         "GET",
+			acceptEncoding: "gzip",
+			respond: func(c Context) error {
+				c.SetResponseHeader("Content-Type", "image/png")
+				return c.RespondOKWithBase64Data([]byte(large))
+			},
+		},
+		{
+			name:           "head",
+			method:         "HEAD",
+			acceptEncoding: "gzip",
+			respond:        func(c Context) error { return c.RespondOKWithText(large) },
+		},
+	}
+	for _, tt := range tests {
+		request := events.LambdaFunctionURLRequest{
+			Headers: map[string]string{"accept-encoding": tt.acceptEncoding},
+			RequestContext: events.LambdaFunctionURLRequestContext{
+				HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: tt.method, Path: "/"},
+			},
+		}
+		handler := func(c Context) error {
+			c.EnableCompression(0, compress.Deflate, compress.Gzip)
+			return tt.respond(c)
+		}
+
+		check := func(t *testing.T, headers map[string]string, data []byte) {
+			if got := headers["Content-Encoding"]; got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := headers["Vary"]; got != tt.wantVary {
+				t.Errorf("Vary = %q, want %q", got, tt.wantVary)
+			}
+			if got := headers["Etag"]; got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if tt.method == "HEAD" || tt.name == "too small" {
+				return
+			}
+			if got := decompress(t, tt.wantEncoding, data); got != large {
+				t.Errorf("Body has %d bytes, want %d", len(got), len(large))
+			}
+		}
+
+		t.Run(tt.name+"/buffered", func(t *testing.T) {
+			response, err := NewHandler(handler)(context.Background(), request)
+			if err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+
+			data := []byte(response.Body)
+			if response.IsBase64Encoded {
+				if data, err = base64.StdEncoding.DecodeString(response.Body); err != nil {
+					t.Fatalf("decode body error = %v", err)
+				}
+			}
+			if got := response.Headers["Content-Length"]; tt.wantEncoding != "" && got != strconv.Itoa(len(data)) {
+				t.Errorf("Content-Length = %s, want %d", got, len(data))
+			}
+			check(t, response.Headers, data)
+		})
+
+		t.Run(tt.name+"/streaming", func(t *testing.T) {
+			response, err := NewStreamingHandler(handler)(context.Background(), request)
+			if err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+
+			if _, ok := response.Headers["Content-Length"]; tt.wantEncoding != "" && ok {
+				t.Errorf("Content-Length is present")
+			}
+			data, err := io.ReadAll(response.Body)
+			if err != nil {
+				t.Fatalf("read body error = %v", err)
+			}
+			check(t, response.Headers, data)
+		})
+	}
+}
+
+func decompress(t *testing.T, encoding string, data []byte) string {
+	if encoding == "" {
+		return string(data)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	data, err = io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read gzip error = %v", err)
+	}
+	return string(data)
+}
@@ -0,0 +1,104 @@
+package lambdafunctionurl
+
+import (
+	"github.com/nguyengg/golambda/lambdafunctionurl/etag"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+func (c *baseContext[T]) EvaluatePreconditions(v interface{}) (int, error) {
+	v = absentIfNil(v)
+	method := c.RequestMethod()
+	safe := method == http.MethodGet || method == http.MethodHead
+
+	var (
+		current      *etag.ETag
+		lastModified time.Time
+	)
+	if i, ok := v.(HasETag); ok {
+		e := i.GetETag()
+		current = &e
+	}
+	if i, ok := v.(HasLastModified); ok {
+		// HTTP-date only has second precision.
+		lastModified = i.GetLastModified().Truncate(time.Second)
+	}
+
+	// https://www.rfc-editor.org/rfc/rfc9110#section-13.2.2 step 1 and 2.
+	ifMatch, err := c.ParseIfMatch()
+	if err != nil {
+		return 0, err
+	}
+	if ifMatch != nil {
+		if v == nil || (!ifMatch.Any && (current == nil || !ifMatch.MatchStrong(*current))) {
+			return http.StatusPreconditionFailed, nil
+		}
+	} else if t, err := c.ParseIfUnmodifiedSince(); err == nil && !t.IsZero() && !lastModified.IsZero() && lastModified.After(t) {
+		// an invalid date must be ignored.
+		return http.StatusPreconditionFailed, nil
+	}
+
+	// step 3 and 4.
+	ifNoneMatch, err := c.ParseIfNoneMatch()
+	if err != nil {
+		return 0, err
+	}
+	if ifNoneMatch != nil {
+		if v != nil && (ifNoneMatch.Any || (current != nil && ifNoneMatch.MatchWeak(*current))) {
+			if safe {
+				return http.StatusNotModified, nil
+			}
+			return http.StatusPreconditionFailed, nil
+		}
+	} else if safe {
+		if t, err := c.ParseIfModifiedSince(); err == nil && !t.IsZero() && !lastModified.IsZero() && !lastModified.After(t) {
+			return http.StatusNotModified, nil
+		}
+	}
+
+	return 0, nil
+}
+
+func (c *baseContext[T]) CheckPreconditions(v interface{}) (ok bool, err error) {
+	statusCode, err := c.EvaluatePreconditions(v)
+	switch {
+	case err != nil:
+		return false, c.RespondBadRequest("%s", err.Error())
+	case statusCode == http.StatusNotModified:
+		return false, c.RespondNotModified(v)
+	case statusCode == http.StatusPreconditionFailed:
+		return false, c.RespondFormattedStatus(http.StatusPreconditionFailed)
+	}
+
+	return true, nil
+}
+
+func (c *baseContext[T]) RespondNotModified(v interface{}) (err error) {
+	if err = c.response.RespondText(""); err == nil {
+		c.SetStatusCode(http.StatusNotModified)
+		c.SetResponseCachingHeaders(v)
+	}
+
+	return
+}
+
+func (c *baseContext[T]) RespondConditionally(v interface{}) error {
+	v = absentIfNil(v)
+	if ok, err := c.CheckPreconditions(v); !ok {
+		return err
+	}
+
+	return c.RespondOKWithJSON(v)
+}
+
+// absentIfNil returns nil if v is a nil pointer such as (*Item)(nil) so that it is treated as an absent resource.
+//
+// Without this, the interface holding the nil pointer is not nil, and calling a value-receiver GetETag on it panics.
+func absentIfNil(v interface{}) interface{} {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil
+	}
+
+	return v
+}
@@ -0,0 +1,100 @@
+package lambdafunctionurl
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/lambdafunctionurl/etag"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type item struct {
+	ETag         etag.ETag `json:"-"`
+	LastModified time.Time `json:"-"`
+	Name         string    `json:"name"`
+}
+
+func (i item) GetETag() etag.ETag {
+	return i.ETag
+}
+
+func (i item) GetLastModified() time.Time {
+	return i.LastModified
+}
+
+func TestContext_RespondConditionally(t *testing.T) {
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 600, time.UTC)
+	v := item{ETag: etag.NewStrongETag("abc"), LastModified: lastModified, Name: "test"}
+	before := lastModified.Add(-time.Hour).Format(http.TimeFormat)
+	after := lastModified.Add(time.Hour).Format(http.TimeFormat)
+
+	tests := []struct {
+		name       string
+		method     string
+		headers    map[string]string
+		missing    bool
+		nilPointer bool
+		wantStatus int
+	}{
+		{name: "unconditional", method: "GET", wantStatus: 200},
+		{name: "if-none-match matches", method: "GET", headers: map[string]string{"if-none-match": `"abc"`}, wantStatus: 304},
+		{name: "if-none-match matches weakly", method: "GET", headers: map[string]string{"if-none-match": `W/"abc"`}, wantStatus: 304},
+		{name: "if-none-match any", method: "HEAD", headers: map[string]string{"if-none-match": `*`}, wantStatus: 304},
+		{name: "if-none-match does not match", method: "GET", headers: map[string]string{"if-none-match": `"xyz"`}, wantStatus: 200},
+		{name: "if-none-match on unsafe method", method: "PUT", headers: map[string]string{"if-none-match": `"abc"`}, wantStatus: 412},
+		{name: "if-none-match any on missing resource", method: "PUT", headers: map[string]string{"if-none-match": `*`}, missing: true, wantStatus: 200},
+		{name: "if-none-match takes precedence over if-modified-since", method: "GET", headers: map[string]string{"if-none-match": `"xyz"`, "if-modified-since": after}, wantStatus: 200},
+		{name: "if-modified-since not modified", method: "GET", headers: map[string]string{"if-modified-since": lastModified.Format(http.TimeFormat)}, wantStatus: 304},
+		{name: "if-modified-since modified", method: "GET", headers: map[string]string{"if-modified-since": before}, wantStatus: 200},
+		{name: "if-modified-since ignored on unsafe method", method: "POST", headers: map[string]string{"if-modified-since": after}, wantStatus: 200},
+		{name: "if-modified-since invalid", method: "GET", headers: map[string]string{"if-modified-since": "yesterday"}, wantStatus: 200},
+		{name: "if-match matches", method: "PUT", headers: map[string]string{"if-match": `"abc"`}, wantStatus: 200},
+		{name: "if-match requires strong comparison", method: "PUT", headers: map[string]string{"if-match": `W/"abc"`}, wantStatus: 412},
+		{name: "if-match does not match", method: "DELETE", headers: map[string]string{"if-match": `"xyz"`}, wantStatus: 412},
+		{name: "if-match any", method: "PUT", headers: map[string]string{"if-match": `*`}, wantStatus: 200},
+		{name: "if-match any on missing resource", method: "PUT", headers: map[string]string{"if-match": `*`}, missing: true, wantStatus: 412},
+		{name: "if-match any on nil pointer", method: "PUT", headers: map[string]string{"if-match": `*`}, nilPointer: true, wantStatus: 412},
+		{name: "if-match on nil pointer", method: "PUT", headers: map[string]string{"if-match": `"abc"`}, nilPointer: true, wantStatus: 412},
+		{name: "if-none-match any on nil pointer", method: "PUT", headers: map[string]string{"if-none-match": `*`}, nilPointer: true, wantStatus: 200},
+		{name: "if-match takes precedence over if-unmodified-since", method: "PUT", headers: map[string]string{"if-match": `"abc"`, "if-unmodified-since": before}, wantStatus: 200},
+		{name: "if-unmodified-since modified", method: "PUT", headers: map[string]string{"if-unmodified-since": before}, wantStatus: 412},
+		{name: "if-unmodified-since not modified", method: "PUT", headers: map[string]string{"if-unmodified-since": after}, wantStatus: 200},
+		{name: "if-match fails before if-none-match", method: "GET", headers: map[string]string{"if-match": `"xyz"`, "if-none-match": `"abc"`}, wantStatus: 412},
+		{name: "invalid if-match", method: "PUT", headers: map[string]string{"if-match": `abc`}, wantStatus: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current interface{} = v
+			if tt.missing {
+				current = nil
+			}
+			if tt.nilPointer {
+				current = (*item)(nil)
+			}
+
+			response, err := NewHandler(func(c Context) error {
+				return c.RespondConditionally(current)
+			})(context.Background(), events.LambdaFunctionURLRequest{
+				Headers: tt.headers,
+				RequestContext: events.LambdaFunctionURLRequestContext{
+					HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: tt.method, Path: "/"},
+				},
+			})
+			if err != nil {
+				t.Fatalf("RespondConditionally() error = %v", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusNotModified {
+				if response.Body != "" {
+					t.Errorf("Body = %q, want empty", response.Body)
+				}
+				if got := response.Headers["Etag"]; got != `"abc"` {
+					t.Errorf("ETag = %q, want %q", got, `"abc"`)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package lambdafunctionurl
+
+import (
+	"github.com/nguyengg/golambda/httpcontext"
+	"github.com/nguyengg/golambda/lambdafunctionurl/etag"
+	"net/http"
+)
+
+// DefaultContentETagMaxSize is an alias of httpcontext.DefaultContentETagMaxSize.
+const DefaultContentETagMaxSize = httpcontext.DefaultContentETagMaxSize
+
+// contentHasher is implemented by buffered.Response and streaming.Response to support content-based ETag.
+type contentHasher interface {
+	Header(key string) string
+	ContentETag(maxSize int64) (e etag.ETag, ok bool, err error)
+	ClearBody()
+}
+
+func (c *baseContext[T]) EnableContentETag(maxSize int64) {
+	if maxSize <= 0 {
+		maxSize = DefaultContentETagMaxSize
+	}
+
+	c.contentETag = true
+	c.contentETagMaxSize = maxSize
+}
+
+// applyContentETag is called after the handler has returned successfully.
+func (c *baseContext[T]) applyContentETag() error {
+	if !c.contentETag || c.StatusCode() != http.StatusOK {
+		return nil
+	}
+
+	h, ok := c.response.(contentHasher)
+	if !ok || h.Header("ETag") != "" {
+		return nil
+	}
+
+	e, ok, err := h.ContentETag(c.contentETagMaxSize)
+	if err != nil || !ok {
+		return err
+	}
+
+	c.SetResponseHeader("ETag", e.String())
+
+	if method := c.RequestMethod(); method != http.MethodGet && method != http.MethodHead {
+		return nil
+	}
+
+	// a malformed If-None-Match is ignored since the full response is still valid.
+	if d, err := c.ParseIfNoneMatch(); err == nil && d != nil && d.MatchWeak(e) {
+		h.ClearBody()
+		c.SetStatusCode(http.StatusNotModified)
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package lambdafunctionurl
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/lambdafunctionurl/etag"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestContext_EnableContentETag(t *testing.T) {
+	body := `{"name":"test"}`
+	want := etag.FromContent([]byte(body)).String()
+
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		respond     func(c Context) error
+		wantStatus  int
+		wantETag    string
+	}{
+		{
+			name:       "json",
+			method:     "GET",
+			respond:    func(c Context) error { return c.RespondOKWithJSON(map[string]string{"name": "test"}) },
+			wantStatus: 200,
+			wantETag:   want,
+		},
+		{
+			name:       "body",
+			method:     "GET",
+			respond:    func(c Context) error { return c.RespondOKWithBody(strings.NewReader(body)) },
+			wantStatus: 200,
+			wantETag:   want,
+		},
+		{
+			name:        "not modified",
+			method:      "GET",
+			ifNoneMatch: want,
+			respond:     func(c Context) error { return c.RespondOKWithText(body) },
+			wantStatus:  304,
+			wantETag:    want,
+		},
+		{
+			name:        "not modified with weak comparison",
+			method:      "HEAD",
+			ifNoneMatch: `"other", W/` + want,
+			respond:     func(c Context) error { return c.RespondOKWithText(body) },
+			wantStatus:  304,
+			wantETag:    want,
+		},
+		{
+			name:        "unsafe method",
+			method:      "POST",
+			ifNoneMatch: want,
+			respond:     func(c Context) error { return c.RespondOKWithText(body) },
+			wantStatus:  200,
+			wantETag:    want,
+		},
+		{
+			name:   "existing etag",
+			method: "GET",
+			respond: func(c Context) error {
+				c.SetResponseHeader("ETag", `"v1"`)
+				return c.RespondOKWithText(body)
+			},
+			wantStatus: 200,
+			wantETag:   `"v1"`,
+		},
+		{
+			name:       "not ok",
+			method:     "GET",
+			respond:    func(c Context) error { return c.RespondNotFound() },
+			wantStatus: 404,
+		},
+	}
+	for _, tt := range tests {
+		request := events.LambdaFunctionURLRequest{
+			Headers: map[string]string{"if-none-match": tt.ifNoneMatch},
+			RequestContext: events.LambdaFunctionURLRequestContext{
+				HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: tt.method, Path: "/"},
+			},
+		}
+		handler := func(c Context) error {
+			c.EnableContentETag(0)
+			return tt.respond(c)
+		}
+
+		t.Run(tt.name+"/buffered", func(t *testing.T) {
+			response, err := NewHandler(handler)(context.Background(), request)
+			if err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if got := response.Headers["Etag"]; got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if tt.wantStatus == http.StatusNotModified {
+				if response.Body != "" {
+					t.Errorf("Body = %q, want empty", response.Body)
+				}
+				if _, ok := response.Headers["Content-Length"]; ok {
+					t.Errorf("Content-Length is present")
+				}
+			}
+		})
+
+		t.Run(tt.name+"/streaming", func(t *testing.T) {
+			response, err := NewStreamingHandler(handler)(context.Background(), request)
+			if err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if got := response.Headers["Etag"]; got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if tt.wantStatus == http.StatusNotModified && response.Body != nil {
+				t.Errorf("Body is not nil")
+			}
+			if tt.wantStatus == http.StatusOK {
+				data, err := io.ReadAll(response.Body)
+				if err != nil {
+					t.Fatalf("read body error = %v", err)
+				}
+				if string(data) != body {
+					t.Errorf("Body = %q, want %q", data, body)
+				}
+			}
+		})
+	}
+}
+
+func TestContext_EnableContentETag_streamingTooLarge(t *testing.T) {
+	body := strings.Repeat("a", 100)
+
+	response, err := NewStreamingHandler(func(c Context) error {
+		c.EnableContentETag(10)
+		return c.RespondOKWithBody(strings.NewReader(body))
+	})(context.Background(), events.LambdaFunctionURLRequest{
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: "GET", Path: "/"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	if got, ok := response.Headers["Etag"]; ok {
+		t.Errorf("ETag = %q, want none", got)
+	}
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("read body error = %v", err)
+	}
+	if string(data) != body {
+		t.Errorf("Body = %q, want %q", data, body)
+	}
+}
@@ -1,7 +1,10 @@
 package etag
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
+	"hash"
 	"regexp"
 	"strings"
 )
@@ -47,8 +50,9 @@ var strongETag = regexp.MustCompile(`^"(?P<value>.+)"$`)
 
 // ParseDirectives parses the "If-Match" or "If-None-Match" header value and returns the directives.
 //
-// If value is empty, return nil, nil,
+// The ETags are separated by commas with optional whitespace around them. If value is empty, return nil, nil,
 func ParseDirectives(value string) (*Directives, error) {
+	value = strings.TrimSpace(value)
 	if value == "" {
 		return nil, nil
 	}
@@ -57,13 +61,13 @@ func ParseDirectives(value string) (*Directives, error) {
 		return &Directives{Any: true}, nil
 	}
 
-	values := strings.Split(value, ", ")
-	if len(values) == 0 {
-		return nil, fmt.Errorf("no ETag values")
-	}
-
 	etags := make([]ETag, 0)
-	for _, v := range values {
+	for _, v := range strings.Split(value, ",") {
+		// https://www.rfc-editor.org/rfc/rfc9110#section-5.6.1.2 requires empty list elements to be ignored.
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+
 		m := weakETag.FindAllStringSubmatch(v, -1)
 		if len(m) == 1 {
 			etags = append(etags, NewWeakETag(m[0][1]))
@@ -79,6 +83,10 @@ func ParseDirectives(value string) (*Directives, error) {
 		return nil, fmt.Errorf("invalid request ETag header")
 	}
 
+	if len(etags) == 0 {
+		return nil, fmt.Errorf("no ETag values")
+	}
+
 	return &Directives{ETags: etags}, nil
 }
 
@@ -96,3 +104,62 @@ func (d Directives) Match(value string) bool {
 
 	return false
 }
+
+// MatchStrong returns true if [Directives.Any] is true or if one of the [Directives.ETags] is strongly equal to the given
+// ETag, meaning neither is weak and their values are the same.
+//
+// "If-Match" uses strong comparison. See https://www.rfc-editor.org/rfc/rfc9110#section-8.8.3.2.
+func (d Directives) MatchStrong(e ETag) bool {
+	if d.Any {
+		return true
+	}
+
+	if e.Weak {
+		return false
+	}
+
+	for _, etag := range d.ETags {
+		if !etag.Weak && etag.Value == e.Value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchWeak returns true if [Directives.Any] is true or if one of the [Directives.ETags] is weakly equal to the given
+// ETag, meaning their values are the same regardless of either being weak.
+//
+// "If-None-Match" uses weak comparison. See https://www.rfc-editor.org/rfc/rfc9110#section-8.8.3.2.
+func (d Directives) MatchWeak(e ETag) bool {
+	return d.Match(e.Value)
+}
+
+// Hasher computes a strong ETag from content that is written to it in chunks.
+//
+// The ETag value is the unpadded base64url encoding of the SHA-256 digest of the content.
+type Hasher struct {
+	h hash.Hash
+}
+
+// NewHasher returns a new Hasher.
+func NewHasher() *Hasher {
+	return &Hasher{h: sha256.New()}
+}
+
+// Write implements io.Writer and never returns an error.
+func (h *Hasher) Write(p []byte) (int, error) {
+	return h.h.Write(p)
+}
+
+// ETag returns the strong ETag of the content written so far.
+func (h *Hasher) ETag() ETag {
+	return NewStrongETag(base64.RawURLEncoding.EncodeToString(h.h.Sum(nil)))
+}
+
+// FromContent returns the strong ETag of the given content.
+func FromContent(data []byte) ETag {
+	h := NewHasher()
+	_, _ = h.Write(data)
+	return h.ETag()
+}
@@ -40,6 +40,25 @@ func TestParseDirectives(t *testing.T) {
 				NewWeakETag("7892dd"),
 			}},
 		},
+		{
+			name: "optional whitespace",
+			args: args{value: " \"a\",\"b\" ,\tW/\"c\" , ,"},
+			want: &Directives{ETags: []ETag{
+				NewStrongETag("a"),
+				NewStrongETag("b"),
+				NewWeakETag("c"),
+			}},
+		},
+		{
+			name:    "only commas",
+			args:    args{value: ", ,"},
+			wantErr: true,
+		},
+		{
+			name:    "unquoted value",
+			args:    args{value: "\"a\", b"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -54,3 +73,64 @@ func TestParseDirectives(t *testing.T) {
 		})
 	}
 }
+
+func TestDirectives_MatchStrong(t *testing.T) {
+	tests := []struct {
+		name       string
+		directives Directives
+		etag       ETag
+		want       bool
+	}{
+		{name: "any", directives: Directives{Any: true}, etag: NewWeakETag("1"), want: true},
+		{name: "both strong", directives: Directives{ETags: []ETag{NewStrongETag("1")}}, etag: NewStrongETag("1"), want: true},
+		{name: "weak directive", directives: Directives{ETags: []ETag{NewWeakETag("1")}}, etag: NewStrongETag("1"), want: false},
+		{name: "weak etag", directives: Directives{ETags: []ETag{NewStrongETag("1")}}, etag: NewWeakETag("1"), want: false},
+		{name: "different values", directives: Directives{ETags: []ETag{NewStrongETag("1")}}, etag: NewStrongETag("2"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.directives.MatchStrong(tt.etag); got != tt.want {
+				t.Errorf("MatchStrong() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirectives_MatchWeak(t *testing.T) {
+	tests := []struct {
+		name       string
+		directives Directives
+		etag       ETag
+		want       bool
+	}{
+		{name: "any", directives: Directives{Any: true}, etag: NewStrongETag("1"), want: true},
+		{name: "both strong", directives: Directives{ETags: []ETag{NewStrongETag("1")}}, etag: NewStrongETag("1"), want: true},
+		{name: "weak directive", directives: Directives{ETags: []ETag{NewWeakETag("1")}}, etag: NewStrongETag("1"), want: true},
+		{name: "both weak", directives: Directives{ETags: []ETag{NewWeakETag("1")}}, etag: NewWeakETag("1"), want: true},
+		{name: "different values", directives: Directives{ETags: []ETag{NewWeakETag("1")}}, etag: NewWeakETag("2"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.directives.MatchWeak(tt.etag); got != tt.want {
+				t.Errorf("MatchWeak() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasher(t *testing.T) {
+	h := NewHasher()
+	_, _ = h.Write([]byte("hello, "))
+	_, _ = h.Write([]byte("world!"))
+
+	got := h.ETag()
+	if want := FromContent([]byte("hello, world!")); got != want {
+		t.Errorf("ETag() = %v, want %v", got, want)
+	}
+	if got.Weak {
+		t.Errorf("ETag() is weak")
+	}
+	if other := FromContent([]byte("hello, world")); got == other {
+		t.Errorf("ETag() of different content = %v, want different", other)
+	}
+}
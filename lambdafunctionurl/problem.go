@@ -0,0 +1,54 @@
+package lambdafunctionurl
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/nguyengg/golambda/httpcontext"
+	"github.com/nguyengg/golambda/httpcontext/validate"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+func (c *baseContext[T]) RespondProblem(p httpcontext.Problem) error {
+	if p.Status == 0 {
+		p.Status = http.StatusBadRequest
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+
+	n, err := c.response.RespondJSON(p)
+	if err == nil {
+		c.SetStatusCode(p.Status)
+		c.SetResponseHeader("Content-Type", httpcontext.ProblemContentType)
+		c.SetResponseHeader("Content-Length", strconv.FormatInt(int64(n), 10))
+	}
+
+	return err
+}
+
+func (c *baseContext[T]) ValidateRequestBody(v interface{}, opts ...func(decoder *json.Decoder)) (ok bool, err error) {
+	if err = c.UnmarshalRequestBodyWithOpts(v, opts...); err != nil {
+		return false, c.RespondProblem(httpcontext.Problem{
+			Status: http.StatusBadRequest,
+			Detail: "invalid request body: " + err.Error(),
+		})
+	}
+
+	var fieldErrors validate.Errors
+	switch err = validate.Struct(v); {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &fieldErrors):
+		return false, c.RespondProblem(httpcontext.Problem{
+			Status: http.StatusBadRequest,
+			Detail: "request body has " + strconv.Itoa(len(fieldErrors)) + " invalid field(s)",
+			Errors: fieldErrors,
+		})
+	default:
+		log.Printf("ERROR validate request body: %v", err)
+		_ = c.RespondInternalServerError()
+		return false, err
+	}
+}
@@ -0,0 +1,109 @@
+package lambdafunctionurl
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/httpcontext"
+	"github.com/nguyengg/golambda/httpcontext/validate"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestContext_ValidateRequestBody(t *testing.T) {
+	type item struct {
+		Name     string `json:"name" validate:"required"`
+		Quantity int    `json:"quantity" validate:"min=1,max=10"`
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantErrors validate.Errors
+	}{
+		{
+			name:       "valid",
+			body:       `{"name":"apple","quantity":3}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid json",
+			body:       `{"name":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown field",
+			body:       `{"name":"apple","colour":"red"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid fields",
+			body:       `{"quantity":11}`,
+			wantStatus: http.StatusBadRequest,
+			wantErrors: validate.Errors{
+				{Field: "name", Pointer: "#/name", Rule: "required", Detail: "is required"},
+				{Field: "quantity", Pointer: "#/quantity", Rule: "max", Detail: "must be at most 10"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := NewHandler(func(c Context) error {
+				var v item
+				if ok, err := c.ValidateRequestBody(&v, DisallowUnknownFields); !ok {
+					return err
+				}
+				return c.RespondOKWithJSON(v)
+			})(context.Background(), events.LambdaFunctionURLRequest{
+				RequestContext: events.LambdaFunctionURLRequestContext{
+					HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: "POST", Path: "/"},
+				},
+				Body: tt.body,
+			})
+			if err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK {
+				return
+			}
+
+			if got := response.Headers["Content-Type"]; got != httpcontext.ProblemContentType {
+				t.Errorf("Content-Type = %q, want %q", got, httpcontext.ProblemContentType)
+			}
+
+			var p httpcontext.Problem
+			if err = json.Unmarshal([]byte(response.Body), &p); err != nil {
+				t.Fatalf("unmarshal problem error = %v", err)
+			}
+			if p.Status != http.StatusBadRequest || p.Title != "Bad Request" || p.Detail == "" {
+				t.Errorf("Problem = %+v", p)
+			}
+			if !reflect.DeepEqual(p.Errors, tt.wantErrors) {
+				t.Errorf("Errors = %#v, want %#v", p.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestContext_ValidateRequestBody_malformedTag(t *testing.T) {
+	response, err := NewHandler(func(c Context) error {
+		var v struct {
+			Enabled bool `json:"enabled" validate:"min=1"`
+		}
+		if ok, err := c.ValidateRequestBody(&v); !ok {
+			return err
+		}
+		return c.RespondOKWithJSON(v)
+	})(context.Background(), events.LambdaFunctionURLRequest{Body: `{"enabled":true}`})
+	if err == nil {
+		t.Errorf("handler error = nil, want malformed tag error")
+	}
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("StatusCode = %d, want %d", response.StatusCode, http.StatusInternalServerError)
+	}
+}
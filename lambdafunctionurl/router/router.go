@@ -0,0 +1,326 @@
+// Package router dispatches Lambda Function URL requests to handlers by method and path pattern.
+//
+// Usage:
+//
+//	r := router.New()
+//	r.Get("/users/{id}", func(c lambdafunctionurl.Context) error {
+//		return c.RespondOKWithText(router.Param(c, "id"))
+//	})
+//
+//	api := r.Group("/api", authenticate)
+//	api.Post("/files/{key+}", upload)
+//
+//	lambdafunctionurl.StartWrapper(r.Serve)
+package router
+
+import (
+	"fmt"
+	"github.com/nguyengg/golambda/lambdafunctionurl"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MethodAny is the method of routes that match every method.
+const MethodAny = "ANY"
+
+// PropertyKeyRouteKey is the metrics property that contains the method and pattern of the matched route, for example
+// "GET /users/{id}".
+const PropertyKeyRouteKey = "routeKey"
+
+// HandlerFunc is the abstract handler that can be passed to lambdafunctionurl.StartWrapper and
+// lambdafunctionurl.StartStreamingWrapper.
+type HandlerFunc func(c lambdafunctionurl.Context) error
+
+// Middleware decorates a HandlerFunc with cross-cutting concerns such as authentication.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Router matches requests against registered routes.
+//
+// Patterns are paths that can contain path parameters "{name}" matching exactly one non-empty segment, and one greedy
+// path parameter "{name+}" or "*" at the end matching one or more segments. Captured values are available with Param
+// and Params. When several routes match a request, routes with more literal segments win, then routes without greedy
+// parameters, then routes with a specific method over MethodAny.
+//
+// If no route matches the path, the NotFound handler is invoked, which responds with 404 by default. If some routes
+// match the path but not the method, the MethodNotAllowed handler is invoked, which responds with 405 and an "Allow"
+// header listing the methods of those routes by default. Routes for http.MethodGet also match http.MethodHead.
+//
+// Routes must be registered before the router starts serving requests.
+type Router struct {
+	t           *table
+	parent      *Router
+	prefix      string
+	middlewares []Middleware
+}
+
+type table struct {
+	routes           []*route
+	notFound         HandlerFunc
+	methodNotAllowed func(c lambdafunctionurl.Context, allow string) error
+}
+
+type route struct {
+	key      string
+	method   string
+	segments []string
+	shape    string
+	literals int
+	greedy   bool
+	handler  HandlerFunc
+	group    *Router
+}
+
+// New creates an empty Router.
+func New() *Router {
+	return &Router{t: &table{}}
+}
+
+// Use adds middlewares that wrap every route registered with this Router and its groups.
+//
+// Middlewares are applied in the order they are given, with the first one being the outermost. Middlewares of a parent
+// Router wrap those of its groups. The middlewares of the root Router also wrap the NotFound and MethodNotAllowed
+// handlers.
+func (r *Router) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// Group creates a child Router whose routes have the given prefix and are wrapped with the given middlewares in
+// addition to the middlewares of this Router.
+func (r *Router) Group(prefix string, middlewares ...Middleware) *Router {
+	return &Router{
+		t:           r.t,
+		parent:      r,
+		prefix:      r.prefix + strings.TrimSuffix(prefix, "/"),
+		middlewares: middlewares,
+	}
+}
+
+// NotFound replaces the handler that is invoked when no route matches the request path.
+func (r *Router) NotFound(handler HandlerFunc) {
+	r.t.notFound = handler
+}
+
+// MethodNotAllowed replaces the handler that is invoked when some routes match the request path but not its method.
+//
+// The allow argument is the comma-separated list of methods that do match the path.
+func (r *Router) MethodNotAllowed(handler func(c lambdafunctionurl.Context, allow string) error) {
+	r.t.methodNotAllowed = handler
+}
+
+// Handle registers the handler for the given method and pattern, wrapped with the given middlewares.
+//
+// Use MethodAny to match every method. Panics if the pattern is invalid or has already been registered for the same
+// method, similar to http.ServeMux.
+func (r *Router) Handle(method, pattern string, handler HandlerFunc, middlewares ...Middleware) {
+	rt, err := parseRoute(strings.ToUpper(method), r.prefix+pattern)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, other := range r.t.routes {
+		if other.method == rt.method && other.shape == rt.shape {
+			panic(fmt.Errorf(`route "%s" conflicts with existing route "%s"`, rt.key, other.key))
+		}
+	}
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	rt.handler = handler
+	rt.group = r
+
+	r.t.routes = append(r.t.routes, rt)
+	sort.SliceStable(r.t.routes, func(i, j int) bool {
+		a, b := r.t.routes[i], r.t.routes[j]
+		if a.literals != b.literals {
+			return a.literals > b.literals
+		}
+		if a.greedy != b.greedy {
+			return b.greedy
+		}
+		return a.method != MethodAny && b.method == MethodAny
+	})
+}
+
+// Get is a convenient method to register a handler for http.MethodGet.
+func (r *Router) Get(pattern string, handler HandlerFunc, middlewares ...Middleware) {
+	r.Handle(http.MethodGet, pattern, handler, middlewares...)
+}
+
+// Post is a convenient method to register a handler for http.MethodPost.
+func (r *Router) Post(pattern string, handler HandlerFunc, middlewares ...Middleware) {
+	r.Handle(http.MethodPost, pattern, handler, middlewares...)
+}
+
+// Put is a convenient method to register a handler for http.MethodPut.
+func (r *Router) Put(pattern string, handler HandlerFunc, middlewares ...Middleware) {
+	r.Handle(http.MethodPut, pattern, handler, middlewares...)
+}
+
+// Patch is a convenient method to register a handler for http.MethodPatch.
+func (r *Router) Patch(pattern string, handler HandlerFunc, middlewares ...Middleware) {
+	r.Handle(http.MethodPatch, pattern, handler, middlewares...)
+}
+
+// Delete is a convenient method to register a handler for http.MethodDelete.
+func (r *Router) Delete(pattern string, handler HandlerFunc, middlewares ...Middleware) {
+	r.Handle(http.MethodDelete, pattern, handler, middlewares...)
+}
+
+// Any is a convenient method to register a handler for MethodAny.
+func (r *Router) Any(pattern string, handler HandlerFunc, middlewares ...Middleware) {
+	r.Handle(MethodAny, pattern, handler, middlewares...)
+}
+
+// Serve dispatches the request to the handler of the matching route.
+//
+// Pass this method to lambdafunctionurl.StartWrapper or lambdafunctionurl.StartStreamingWrapper. The route key of the
+// matched route is added as the PropertyKeyRouteKey metrics property, and its path parameters are attached to the
+// context so that Param and Params can retrieve them.
+func (r *Router) Serve(c lambdafunctionurl.Context) error {
+	method := c.RequestMethod()
+	segments := strings.Split(c.RequestPath(), "/")[1:]
+
+	var allow []string
+	for _, rt := range r.t.routes {
+		params, ok := rt.match(segments)
+		if !ok {
+			continue
+		}
+
+		if rt.method != MethodAny && rt.method != method && (rt.method != http.MethodGet || method != http.MethodHead) {
+			allow = append(allow, rt.method)
+			if rt.method == http.MethodGet {
+				allow = append(allow, http.MethodHead)
+			}
+			continue
+		}
+
+		c.Metrics().SetProperty(PropertyKeyRouteKey, rt.key)
+		c.WithValue(paramsKey{}, params)
+
+		h := rt.handler
+		for g := rt.group; g != nil; g = g.parent {
+			h = chain(g.middlewares, h)
+		}
+		return h(c)
+	}
+
+	if len(allow) == 0 {
+		h := r.t.notFound
+		if h == nil {
+			h = func(c lambdafunctionurl.Context) error {
+				return c.RespondNotFound()
+			}
+		}
+		return chain(r.root().middlewares, h)(c)
+	}
+
+	slices.Sort(allow)
+	allowed := strings.Join(slices.Compact(allow), ", ")
+	h := r.t.methodNotAllowed
+	if h == nil {
+		h = func(c lambdafunctionurl.Context, allow string) error {
+			return c.RespondMethodNotAllowed(allow)
+		}
+	}
+	return chain(r.root().middlewares, func(c lambdafunctionurl.Context) error {
+		return h(c, allowed)
+	})(c)
+}
+
+func (r *Router) root() *Router {
+	for r.parent != nil {
+		r = r.parent
+	}
+
+	return r
+}
+
+func chain(middlewares []Middleware, handler HandlerFunc) HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+type paramsKey struct{}
+
+// Param returns the value of the path parameter captured by the matched route, or empty string if there is no such
+// parameter.
+//
+// The value of the greedy parameter "*" is available as Param(c, "*").
+func Param(c lambdafunctionurl.Context, name string) string {
+	return Params(c)[name]
+}
+
+// Params returns all path parameters captured by the matched route.
+func Params(c lambdafunctionurl.Context) map[string]string {
+	params, _ := c.Value(paramsKey{}).(map[string]string)
+	return params
+}
+
+func parseRoute(method, pattern string) (*route, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf(`pattern "%s" does not start with "/"`, pattern)
+	}
+
+	rt := &route{key: method + " " + pattern, method: method, segments: strings.Split(pattern, "/")[1:]}
+	shape := make([]string, len(rt.segments))
+	for i, s := range rt.segments {
+		shape[i] = "{}"
+		switch {
+		case s == "*" || strings.HasPrefix(s, "{") && strings.HasSuffix(s, "+}"):
+			if i != len(rt.segments)-1 {
+				return nil, fmt.Errorf(`pattern "%s" has greedy path parameter that is not the last segment`, pattern)
+			}
+			rt.greedy = true
+			shape[i] = "{+}"
+		case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}"):
+			if len(s) == 2 {
+				return nil, fmt.Errorf(`pattern "%s" has path parameter without name`, pattern)
+			}
+		default:
+			rt.literals++
+			shape[i] = s
+		}
+	}
+
+	// routes with the same shape differ only in parameter names so they would match the same requests.
+	rt.shape = strings.Join(shape, "/")
+	return rt, nil
+}
+
+func (rt *route) match(segments []string) (map[string]string, bool) {
+	if len(segments) < len(rt.segments) || (!rt.greedy && len(segments) != len(rt.segments)) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, s := range rt.segments {
+		switch {
+		case rt.greedy && i == len(rt.segments)-1:
+			v := strings.Join(segments[i:], "/")
+			if v == "" {
+				return nil, false
+			}
+			if s == "*" {
+				params["*"] = v
+			} else {
+				params[s[1:len(s)-2]] = v
+			}
+		case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}"):
+			if segments[i] == "" {
+				return nil, false
+			}
+			params[s[1:len(s)-1]] = segments[i]
+		case s != segments[i]:
+			return nil, false
+		}
+	}
+
+	return params, true
+}
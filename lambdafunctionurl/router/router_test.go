@@ -0,0 +1,172 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/golambdatest"
+	"github.com/nguyengg/golambda/lambdafunctionurl"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRouter_Serve(t *testing.T) {
+	respond := func(name string) HandlerFunc {
+		return func(c lambdafunctionurl.Context) error {
+			c.SetResponseHeader("X-Route", name)
+			return c.RespondOKWithJSON(Params(c))
+		}
+	}
+
+	r := New()
+	r.Get("/users", respond("list"))
+	r.Post("/users", respond("create"))
+	r.Get("/users/{id}", respond("get"))
+	r.Get("/users/me", respond("me"))
+	r.Delete("/users/{id}", respond("delete"))
+	r.Get("/files/{key+}", respond("files"))
+	r.Any("/static/*", respond("static"))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantRoute  string
+		wantParams map[string]string
+		wantAllow  string
+	}{
+		{name: "exact", method: "GET", path: "/users", wantStatus: 200, wantRoute: "list", wantParams: map[string]string{}},
+		{name: "method", method: "POST", path: "/users", wantStatus: 200, wantRoute: "create", wantParams: map[string]string{}},
+		{name: "param", method: "GET", path: "/users/123", wantStatus: 200, wantRoute: "get", wantParams: map[string]string{"id": "123"}},
+		{name: "literal wins over param", method: "GET", path: "/users/me", wantStatus: 200, wantRoute: "me", wantParams: map[string]string{}},
+		{name: "head matches get", method: "HEAD", path: "/users/123", wantStatus: 200, wantRoute: "get", wantParams: map[string]string{"id": "123"}},
+		{name: "greedy", method: "GET", path: "/files/a/b/c.txt", wantStatus: 200, wantRoute: "files", wantParams: map[string]string{"key": "a/b/c.txt"}},
+		{name: "wildcard", method: "PUT", path: "/static/css/main.css", wantStatus: 200, wantRoute: "static", wantParams: map[string]string{"*": "css/main.css"}},
+		{name: "greedy requires segment", method: "GET", path: "/files/", wantStatus: 404},
+		{name: "not found", method: "GET", path: "/groups", wantStatus: 404},
+		{name: "param requires segment", method: "GET", path: "/users/123/posts", wantStatus: 404},
+		{name: "method not allowed", method: "PUT", path: "/users/123", wantStatus: 405, wantAllow: "DELETE, GET, HEAD"},
+		{name: "method not allowed exact", method: "DELETE", path: "/users", wantStatus: 405, wantAllow: "GET, HEAD, POST"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := lambdafunctionurl.NewHandler(r.Serve)(context.Background(), events.LambdaFunctionURLRequest{
+				RequestContext: events.LambdaFunctionURLRequestContext{
+					HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: tt.method, Path: tt.path},
+				},
+			})
+			if err != nil {
+				t.Fatalf("Serve() error = %v", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if got := response.Headers["X-Route"]; got != tt.wantRoute {
+				t.Errorf("X-Route = %q, want %q", got, tt.wantRoute)
+			}
+			if got := response.Headers["Allow"]; got != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+			}
+			if tt.wantParams != nil {
+				var got map[string]string
+				if err = json.Unmarshal([]byte(response.Body), &got); err != nil {
+					t.Fatalf("unmarshal body error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.wantParams) {
+					t.Errorf("Params() = %v, want %v", got, tt.wantParams)
+				}
+			}
+		})
+	}
+}
+
+func TestRouter_Group(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c lambdafunctionurl.Context) error {
+				calls = append(calls, name)
+				return next(c)
+			}
+		}
+	}
+
+	r := New()
+	r.Use(trace("root"))
+	api := r.Group("/api/", trace("api"))
+	v1 := api.Group("/v1")
+	v1.Use(trace("v1"))
+	v1.Get("/users/{id}", func(c lambdafunctionurl.Context) error {
+		calls = append(calls, "handler:"+Param(c, "id"))
+		return c.RespondOKWithText("")
+	}, trace("route"))
+
+	rec := golambdatest.NewRecorder()
+	h := lambdafunctionurl.Wrap(lambdafunctionurl.NewHandler(r.Serve), rec.Options()...)
+
+	response, err := h(golambdatest.NewContext(context.Background()), events.LambdaFunctionURLRequest{
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: http.MethodGet, Path: "/api/v1/users/123"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want %d", response.StatusCode, http.StatusOK)
+	}
+	if want := []string{"root", "api", "v1", "route", "handler:123"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if got, _ := rec.LastMetrics(t).Property(PropertyKeyRouteKey); got != "GET /api/v1/users/{id}" {
+		t.Errorf("routeKey = %v, want %q", got, "GET /api/v1/users/{id}")
+	}
+
+	// only the root middlewares wrap the not found handler.
+	calls = nil
+	r.NotFound(func(c lambdafunctionurl.Context) error {
+		calls = append(calls, "notFound")
+		return c.RespondNotFound()
+	})
+	response, err = h(golambdatest.NewContext(context.Background()), events.LambdaFunctionURLRequest{
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: http.MethodGet, Path: "/api/v2/users/123"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("StatusCode = %d, want %d", response.StatusCode, http.StatusNotFound)
+	}
+	if want := []string{"root", "notFound"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestRouter_Handle_panics(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+	}{
+		{name: "no leading slash", pattern: "users"},
+		{name: "greedy not last", pattern: "/files/{key+}/meta"},
+		{name: "empty param", pattern: "/users/{}"},
+		{name: "duplicate", pattern: "/users/{name}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.Get("/users/{id}", func(c lambdafunctionurl.Context) error { return nil })
+
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Handle(%q) did not panic", tt.pattern)
+				}
+			}()
+			r.Get(tt.pattern, func(c lambdafunctionurl.Context) error { return nil })
+		})
+	}
+}
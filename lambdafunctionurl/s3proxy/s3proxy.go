@@ -0,0 +1,220 @@
+// Package s3proxy serves S3 objects from Lambda Function URL handlers.
+//
+// In RESPONSE_STREAM mode, the object is piped straight from S3 to the client so there is no payload limit other than
+// the Lambda streaming limit. In BUFFERED mode, the object is read into memory so the ~6MB payload limit applies.
+//
+// Usage:
+//
+//	lambdafunctionurl.StartStreamingWrapper(func(c lambdafunctionurl.Context) error {
+//		return s3proxy.Proxy(c, client, &s3.GetObjectInput{
+//			Bucket: aws.String("my-bucket"),
+//			Key:    aws.String(strings.TrimPrefix(c.RequestPath(), "/")),
+//		})
+//	})
+package s3proxy
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/nguyengg/golambda/lambdafunctionurl"
+	s4 "github.com/nguyengg/golambda/s3"
+	"github.com/nguyengg/golambda/smithyerrors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CounterKeyBytes is the metrics counter of the number of bytes of the object that is sent to the client.
+//
+// Because the body is streamed after the handler returns (and the metrics are logged), the value is the
+// "Content-Length" reported by S3 rather than a count of the bytes that actually reach the client.
+const CounterKeyBytes = "s3ProxyBytes"
+
+// Client abstracts the S3 APIs needed by Proxy; s3.Client implements it.
+type Client interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+}
+
+// Proxy calls S3 GetObject or HeadObject depending on the request method and sets the response accordingly.
+//
+// The input identifies the object with Bucket, Key, and optionally ExpectedBucketOwner and VersionId (see
+// s4.URIWithOwner.Get), and is decorated with the request's conditional and "Range" headers via s4.AddToGetObject. The
+// object's body is passed to [lambdafunctionurl.Context.RespondWithBody] without being read.
+//
+// The status code is http.StatusOK, or http.StatusPartialContent with "Content-Range" if S3 returns a partial object.
+// Because S3 only supports one range per request, multiple ranges (e.g. "bytes=0-99,200-299") are fetched lazily one
+// at a time as the multipart/byteranges body is streamed (see s4.GetObjectRanges).
+// S3's 304 Not Modified and 412 Precondition Failed are passed through, the former with the "ETag" and
+// "Last-Modified" of the object. An unsatisfiable range gets http.StatusRequestedRangeNotSatisfiable with
+// "Content-Range: bytes */size". A missing object gets [lambdafunctionurl.Context.RespondNotFound], and methods other
+// than GET and HEAD get [lambdafunctionurl.Context.RespondMethodNotAllowed]. Any other error is logged and returned
+// after setting the response to [lambdafunctionurl.Context.RespondInternalServerError].
+func Proxy(c lambdafunctionurl.Context, client Client, input *s3.GetObjectInput, optFns ...func(*s3.Options)) error {
+	switch c.RequestMethod() {
+	case http.MethodGet:
+	case http.MethodHead:
+		return proxyHead(c, client, input, optFns...)
+	default:
+		return c.RespondMethodNotAllowed("GET, HEAD")
+	}
+
+	input = s4.AddToGetObject(input, c.RequestHeaders())
+	if input.Range != nil && strings.Contains(*input.Range, ",") {
+		return proxyRanges(c, client, input, optFns...)
+	}
+
+	return proxyGet(c, client, input, optFns...)
+}
+
+// Redirect responds with a redirect to a presigned GetObject URL of the object that expires after the given duration.
+//
+// Use this in BUFFERED mode to serve objects larger than the payload limit: the client downloads the object directly
+// from S3, and because the client sends its own "Range" and conditional headers to S3, those work as well. The
+// statusCode should be http.StatusFound or http.StatusTemporaryRedirect. The URL honours
+// [s4.URIWithOwner.ExpectedBucketOwner] (see s4.URIWithOwner.PresignGet).
+func Redirect(c lambdafunctionurl.Context, client s4.PresignGetObjectAPIClient, uri s4.URIWithOwner, statusCode int, expires time.Duration) error {
+	location, err := uri.PresignGet(c.Context(), client, nil, expires)
+	if err != nil {
+		log.Printf("ERROR presign S3 GetObject: %v", err)
+		_ = c.RespondInternalServerError()
+		return err
+	}
+
+	c.SetResponseHeader("Location", location)
+	c.SetStatusCode(statusCode)
+	return nil
+}
+
+// proxyRanges uses HeadObject to evaluate the conditional headers and find the size of the object, then streams every
+// range with its own GetObject.
+func proxyRanges(c lambdafunctionurl.Context, client Client, input *s3.GetObjectInput, optFns ...func(*s3.Options)) error {
+	hi := headObjectInput(input)
+	hi.IfMatch = input.IfMatch
+	hi.IfModifiedSince = input.IfModifiedSince
+	hi.IfNoneMatch = input.IfNoneMatch
+	hi.IfUnmodifiedSince = input.IfUnmodifiedSince
+	head, err := client.HeadObject(c.Context(), hi, optFns...)
+	if err != nil {
+		return respondError(c, client, input, err, optFns...)
+	}
+
+	size := aws.ToInt64(head.ContentLength)
+	ranges, err := s4.ParseRange(aws.ToString(input.Range), size)
+	if err != nil {
+		c.SetResponseHeader("Content-Range", "bytes */"+strconv.FormatInt(size, 10))
+		return c.RespondFormattedStatus(http.StatusRequestedRangeNotSatisfiable)
+	}
+
+	// the preconditions have passed so every part only needs to match the same version of the object.
+	in := *input
+	in.IfMatch = head.ETag
+	in.IfModifiedSince = nil
+	in.IfNoneMatch = nil
+	in.IfUnmodifiedSince = nil
+
+	if len(ranges) < 2 {
+		in.Range = nil
+		if len(ranges) == 1 {
+			in.Range = aws.String(ranges[0].String())
+		}
+		return proxyGet(c, client, &in, optFns...)
+	}
+
+	body, contentType, contentLength := s4.GetObjectRanges(c.Context(), client, &in, ranges, size, aws.ToString(head.ContentType), optFns...)
+	c.SetResponseHeader("Content-Type", contentType)
+	c.SetResponseHeader("Content-Length", strconv.FormatInt(contentLength, 10))
+	if head.ETag != nil {
+		c.SetResponseHeader("ETag", *head.ETag)
+	}
+	if head.LastModified != nil {
+		c.SetResponseHeader("Last-Modified", head.LastModified.Format(http.TimeFormat))
+	}
+	c.SetStatusCode(http.StatusPartialContent)
+
+	c.Metrics().AddCount(CounterKeyBytes, contentLength)
+	return c.RespondWithBody(body)
+}
+
+func proxyGet(c lambdafunctionurl.Context, client Client, input *s3.GetObjectInput, optFns ...func(*s3.Options)) error {
+	output, err := client.GetObject(c.Context(), input, optFns...)
+	if err != nil {
+		return respondError(c, client, input, err, optFns...)
+	}
+
+	s4.HeadersFromGetObjectOutput(output, c.SetResponseHeader)
+	if output.AcceptRanges != nil {
+		c.SetResponseHeader("Accept-Ranges", *output.AcceptRanges)
+	}
+	if output.ContentRange != nil {
+		c.SetStatusCode(http.StatusPartialContent)
+	} else {
+		c.SetStatusCode(http.StatusOK)
+	}
+
+	c.Metrics().AddCount(CounterKeyBytes, aws.ToInt64(output.ContentLength))
+	return c.RespondWithBody(output.Body)
+}
+
+func proxyHead(c lambdafunctionurl.Context, client Client, input *s3.GetObjectInput, optFns ...func(*s3.Options)) error {
+	output, err := client.HeadObject(c.Context(), s4.AddToHeadObject(headObjectInput(input), c.RequestHeaders()), optFns...)
+	if err != nil {
+		return respondError(c, client, input, err, optFns...)
+	}
+
+	s4.HeadersFromHeadObjectOutput(output, c.SetResponseHeader)
+	if output.AcceptRanges != nil {
+		c.SetResponseHeader("Accept-Ranges", *output.AcceptRanges)
+	}
+	c.SetStatusCode(http.StatusOK)
+	return nil
+}
+
+func headObjectInput(input *s3.GetObjectInput) *s3.HeadObjectInput {
+	return &s3.HeadObjectInput{
+		Bucket:              input.Bucket,
+		Key:                 input.Key,
+		ExpectedBucketOwner: input.ExpectedBucketOwner,
+		VersionId:           input.VersionId,
+	}
+}
+
+func respondError(c lambdafunctionurl.Context, client Client, input *s3.GetObjectInput, err error, optFns ...func(*s3.Options)) error {
+	switch statusCode := smithyerrors.StatusCode(err); statusCode {
+	case http.StatusNotFound:
+		return c.RespondNotFound()
+	case http.StatusNotModified:
+		// 304 must carry the validators that a 200 would have.
+		var re *awshttp.ResponseError
+		if errors.As(err, &re) && re.Response != nil {
+			for _, k := range []string{"Cache-Control", "ETag", "Expires", "Last-Modified"} {
+				if v := re.Response.Header.Get(k); v != "" {
+					c.SetResponseHeader(k, v)
+				}
+			}
+		}
+		c.SetStatusCode(http.StatusNotModified)
+		return nil
+	case http.StatusPreconditionFailed:
+		return c.RespondFormattedStatus(statusCode)
+	case http.StatusRequestedRangeNotSatisfiable:
+		// S3 doesn't return the size of the object so ask for it without the range and conditions.
+		if output, err := client.HeadObject(c.Context(), headObjectInput(input), optFns...); err == nil && output.ContentLength != nil {
+			c.SetResponseHeader("Content-Range", "bytes */"+strconv.FormatInt(*output.ContentLength, 10))
+		}
+		return c.RespondFormattedStatus(statusCode)
+	}
+
+	if s4.IsNoSuchKey(err) {
+		return c.RespondNotFound()
+	}
+
+	log.Printf("ERROR proxy S3: %v", err)
+	_ = c.RespondInternalServerError()
+	return err
+}
@@ -0,0 +1,264 @@
+package s3proxy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/nguyengg/golambda/lambdafunctionurl"
+	s4 "github.com/nguyengg/golambda/s3"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeClient struct {
+	get  func(input *s3.GetObjectInput) (*s3.GetObjectOutput, error)
+	head func(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error)
+}
+
+func (f fakeClient) GetObject(_ context.Context, input *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return f.get(input)
+}
+
+func (f fakeClient) HeadObject(_ context.Context, input *s3.HeadObjectInput, _ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return f.head(input)
+}
+
+func responseError(statusCode int, header http.Header) error {
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: statusCode, Header: header}},
+			Err:      io.EOF,
+		},
+	}
+}
+
+func TestProxy(t *testing.T) {
+	const content = "hello, world!"
+
+	client := fakeClient{
+		get: func(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+			if aws.ToString(input.Key) == "missing" {
+				return nil, responseError(http.StatusNotFound, http.Header{})
+			}
+
+			switch aws.ToString(input.IfNoneMatch) {
+			case `"abc"`:
+				return nil, responseError(http.StatusNotModified, http.Header{"Etag": {`"abc"`}})
+			}
+			switch aws.ToString(input.IfMatch) {
+			case `"xyz"`:
+				return nil, responseError(http.StatusPreconditionFailed, http.Header{})
+			}
+
+			if input.Range == nil {
+				return &s3.GetObjectOutput{
+					Body:          io.NopCloser(strings.NewReader(content)),
+					ContentLength: aws.Int64(int64(len(content))),
+					ContentType:   aws.String("text/plain"),
+					ETag:          aws.String(`"abc"`),
+					AcceptRanges:  aws.String("bytes"),
+				}, nil
+			}
+
+			first, last, _ := strings.Cut(strings.TrimPrefix(*input.Range, "bytes="), "-")
+			start, _ := strconv.Atoi(first)
+			end, _ := strconv.Atoi(last)
+			if start >= len(content) {
+				return nil, responseError(http.StatusRequestedRangeNotSatisfiable, http.Header{})
+			}
+			return &s3.GetObjectOutput{
+				Body:          io.NopCloser(strings.NewReader(content[start : end+1])),
+				ContentLength: aws.Int64(int64(end - start + 1)),
+				ContentRange:  aws.String(fmt.Sprintf("bytes %d-%d/%d", start, end, len(content))),
+			}, nil
+		},
+		head: func(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+			return &s3.HeadObjectOutput{
+				ContentLength: aws.Int64(int64(len(content))),
+				ContentType:   aws.String("text/plain"),
+				ETag:          aws.String(`"abc"`),
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		key         string
+		headers     map[string]string
+		wantStatus  int
+		wantHeaders map[string]string
+		wantBody    string
+	}{
+		{
+			name:        "get",
+			method:      "GET",
+			wantStatus:  200,
+			wantHeaders: map[string]string{"Content-Length": "13", "Etag": `"abc"`, "Accept-Ranges": "bytes"},
+			wantBody:    content,
+		},
+		{
+			name:        "range",
+			method:      "GET",
+			headers:     map[string]string{"range": "bytes=0-4"},
+			wantStatus:  206,
+			wantHeaders: map[string]string{"Content-Length": "5", "Content-Range": "bytes 0-4/13"},
+			wantBody:    "hello",
+		},
+		{
+			name:        "multiple ranges",
+			method:      "GET",
+			headers:     map[string]string{"range": "bytes=0-4, 7-11"},
+			wantStatus:  206,
+			wantHeaders: map[string]string{"Etag": `"abc"`},
+			wantBody:    "hello|world",
+		},
+		{
+			name:        "multiple ranges not satisfiable",
+			method:      "GET",
+			headers:     map[string]string{"range": "bytes=100-,200-"},
+			wantStatus:  416,
+			wantHeaders: map[string]string{"Content-Range": "bytes */13"},
+		},
+		{
+			name:        "range not satisfiable",
+			method:      "GET",
+			headers:     map[string]string{"range": "bytes=100-"},
+			wantStatus:  416,
+			wantHeaders: map[string]string{"Content-Range": "bytes */13"},
+		},
+		{
+			name:        "not modified",
+			method:      "GET",
+			headers:     map[string]string{"if-none-match": `"abc"`},
+			wantStatus:  304,
+			wantHeaders: map[string]string{"Etag": `"abc"`},
+		},
+		{
+			name:       "precondition failed",
+			method:     "GET",
+			headers:    map[string]string{"if-match": `"xyz"`},
+			wantStatus: 412,
+		},
+		{
+			name:       "not found",
+			method:     "GET",
+			key:        "missing",
+			wantStatus: 404,
+		},
+		{
+			name:        "head",
+			method:      "HEAD",
+			wantStatus:  200,
+			wantHeaders: map[string]string{"Content-Length": "13", "Etag": `"abc"`},
+		},
+		{
+			name:        "method not allowed",
+			method:      "POST",
+			wantStatus:  405,
+			wantHeaders: map[string]string{"Allow": "GET, HEAD"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := tt.key
+			if key == "" {
+				key = "key"
+			}
+
+			response, err := lambdafunctionurl.NewStreamingHandler(func(c lambdafunctionurl.Context) error {
+				return Proxy(c, client, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String(key)})
+			})(context.Background(), events.LambdaFunctionURLRequest{
+				Headers: tt.headers,
+				RequestContext: events.LambdaFunctionURLRequestContext{
+					HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: tt.method, Path: "/" + key},
+				},
+			})
+			if err != nil {
+				t.Fatalf("Proxy() error = %v", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			for k, v := range tt.wantHeaders {
+				if got := response.Headers[k]; got != v {
+					t.Errorf("%s = %q, want %q", k, got, v)
+				}
+			}
+			if tt.wantBody == "" {
+				return
+			}
+
+			data, err := io.ReadAll(response.Body)
+			if err != nil {
+				t.Fatalf("read body error = %v", err)
+			}
+			if got := response.Headers["Content-Length"]; got != strconv.Itoa(len(data)) {
+				t.Errorf("Content-Length = %s, want %d", got, len(data))
+			}
+
+			mediaType, params, _ := mime.ParseMediaType(response.Headers["Content-Type"])
+			if mediaType == "multipart/byteranges" {
+				var parts []string
+				r := multipart.NewReader(bytes.NewReader(data), params["boundary"])
+				for p, err := r.NextPart(); err == nil; p, err = r.NextPart() {
+					b, _ := io.ReadAll(p)
+					parts = append(parts, string(b))
+				}
+				data = []byte(strings.Join(parts, "|"))
+			}
+			if string(data) != tt.wantBody {
+				t.Errorf("Body = %q, want %q", data, tt.wantBody)
+			}
+		})
+	}
+}
+
+type fakePresignClient struct {
+	input *s3.GetObjectInput
+}
+
+func (f *fakePresignClient) PresignGetObject(_ context.Context, input *s3.GetObjectInput, _ ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error) {
+	f.input = input
+	return &v4.PresignedHTTPRequest{
+		URL:    "https://" + aws.ToString(input.Bucket) + ".s3.amazonaws.com/" + aws.ToString(input.Key) + "?X-Amz-Signature=abc",
+		Method: http.MethodGet,
+	}, nil
+}
+
+func TestRedirect(t *testing.T) {
+	client := &fakePresignClient{}
+	uri := s4.URIWithOwner{Bucket: "bucket", Key: "key", ExpectedBucketOwner: "1234"}
+
+	response, err := lambdafunctionurl.NewHandler(func(c lambdafunctionurl.Context) error {
+		return Redirect(c, client, uri, http.StatusTemporaryRedirect, time.Minute)
+	})(context.Background(), events.LambdaFunctionURLRequest{
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{Method: "GET", Path: "/key"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Redirect() error = %v", err)
+	}
+	if response.StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("StatusCode = %d, want %d", response.StatusCode, http.StatusTemporaryRedirect)
+	}
+	if got, want := response.Headers["Location"], "https://bucket.s3.amazonaws.com/key?X-Amz-Signature=abc"; got != want {
+		t.Errorf("Location = %q, want %q", got, want)
+	}
+	if got := aws.ToString(client.input.ExpectedBucketOwner); got != "1234" {
+		t.Errorf("ExpectedBucketOwner = %q, want %q", got, "1234")
+	}
+}
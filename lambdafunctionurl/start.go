@@ -9,9 +9,12 @@ import (
 	"github.com/nguyengg/golambda/lambdafunctionurl/buffered"
 	"github.com/nguyengg/golambda/lambdafunctionurl/streaming"
 	"github.com/nguyengg/golambda/logsupport"
-	"github.com/nguyengg/golambda/metrics"
 	"github.com/nguyengg/golambda/start"
+	"github.com/nguyengg/golambda/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"log"
+	"net/http"
+	"strings"
 )
 
 // Handler handles requests to Lambda Function URLs in BUFFERED invoke mode.
@@ -23,12 +26,22 @@ type StreamingHandler func(ctx context.Context, request events.LambdaFunctionURL
 // Start starts the Lambda runtime loop with the specified Handler.
 func Start(handler Handler, options ...start.Option) {
 	opts := start.New(options)
+	lambda.StartWithOptions(wrap(handler, opts), opts.HandlerOptions...)
+}
+
+// Wrap returns the Handler that Start passes to the Lambda runtime, which is the given handler wrapped with metrics,
+// logging, and middlewares according to the options.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. with a local server or in tests.
+func Wrap(handler Handler, options ...start.Option) Handler {
+	return wrap(handler, start.New(options))
+}
 
-	lambda.StartHandlerFunc(func(ctx context.Context, request events.LambdaFunctionURLRequest) (response events.LambdaFunctionURLResponse, err error) {
-		m := metrics.NewSimpleMetricsContext(
-			opts.LoggerProvider(ctx).WithContext(ctx),
-			request.RequestContext.RequestID,
-			request.RequestContext.TimeEpoch)
+func wrap(handler Handler, opts *start.Options) Handler {
+	h := start.Chain(opts, start.Handler[events.LambdaFunctionURLRequest, events.LambdaFunctionURLResponse](handler))
+
+	return func(ctx context.Context, request events.LambdaFunctionURLRequest) (response events.LambdaFunctionURLResponse, err error) {
+		m := opts.NewMetrics(ctx, request.RequestContext.RequestID, request.RequestContext.TimeEpoch)
 		ctx = m.WithContext(ctx)
 
 		if !opts.DisableSetUpGlobalLogger {
@@ -57,6 +70,14 @@ func Start(handler Handler, options ...start.Option) {
 
 		panicked := true
 
+		ctx, span := opts.StartSpan(ctx, "lambdafunctionurl",
+			attribute.String("path", request.RequestContext.HTTP.Path),
+			attribute.String("method", request.RequestContext.HTTP.Method))
+		defer func() {
+			tracing.SetStatusCode(span, response.StatusCode)
+			tracing.End(span, err, panicked)
+		}()
+
 		if !opts.DisableMetricsLogging {
 			m.
 				SetProperty("path", request.RequestContext.HTTP.Path).
@@ -74,21 +95,40 @@ func Start(handler Handler, options ...start.Option) {
 			}()
 		}
 
-		response, err = handler(ctx, request)
+		if opts.RecoverPanics {
+			defer func() {
+				if r := recover(); r != nil {
+					_ = opts.Recover(ctx, r)
+					response, err = internalServerError(), nil
+				}
+			}()
+		}
+
+		response, err = h(ctx, request)
 		panicked = false
 		return
-	}, opts.HandlerOptions...)
+	}
 }
 
 // StartStreaming starts the Lambda runtime loop with the specified StreamingHandler.
 func StartStreaming(handler StreamingHandler, options ...start.Option) {
 	opts := start.New(options)
+	lambda.StartWithOptions(wrapStreaming(handler, opts), opts.HandlerOptions...)
+}
+
+// WrapStreaming returns the StreamingHandler that StartStreaming passes to the Lambda runtime, which is the given handler wrapped with metrics,
+// logging, and middlewares according to the options.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. with a local server or in tests.
+func WrapStreaming(handler StreamingHandler, options ...start.Option) StreamingHandler {
+	return wrapStreaming(handler, start.New(options))
+}
 
-	lambda.StartHandlerFunc(func(ctx context.Context, request events.LambdaFunctionURLRequest) (response *events.LambdaFunctionURLStreamingResponse, err error) {
-		m := metrics.NewSimpleMetricsContext(
-			opts.LoggerProvider(ctx).WithContext(ctx),
-			request.RequestContext.RequestID,
-			request.RequestContext.TimeEpoch)
+func wrapStreaming(handler StreamingHandler, opts *start.Options) StreamingHandler {
+	h := start.Chain(opts, start.Handler[events.LambdaFunctionURLRequest, *events.LambdaFunctionURLStreamingResponse](handler))
+
+	return func(ctx context.Context, request events.LambdaFunctionURLRequest) (response *events.LambdaFunctionURLStreamingResponse, err error) {
+		m := opts.NewMetrics(ctx, request.RequestContext.RequestID, request.RequestContext.TimeEpoch)
 		ctx = m.WithContext(ctx)
 
 		if !opts.DisableSetUpGlobalLogger {
@@ -131,6 +171,16 @@ func StartStreaming(handler StreamingHandler, options ...start.Option) {
 
 		panicked := true
 
+		ctx, span := opts.StartSpan(ctx, "lambdafunctionurl",
+			attribute.String("path", request.RequestContext.HTTP.Path),
+			attribute.String("method", request.RequestContext.HTTP.Method))
+		defer func() {
+			if response != nil {
+				tracing.SetStatusCode(span, response.StatusCode)
+			}
+			tracing.End(span, err, panicked)
+		}()
+
 		if !opts.DisableMetricsLogging {
 			m.
 				SetProperty("path", request.RequestContext.HTTP.Path).
@@ -153,34 +203,89 @@ func StartStreaming(handler StreamingHandler, options ...start.Option) {
 			}()
 		}
 
-		response, err = handler(ctx, request)
+		if opts.RecoverPanics {
+			defer func() {
+				if r := recover(); r != nil {
+					_ = opts.Recover(ctx, r)
+					response, err = internalServerErrorStreaming(), nil
+				}
+			}()
+		}
+
+		response, err = h(ctx, request)
 		panicked = false
 		return
-	}, opts.HandlerOptions...)
+	}
 }
 
 // StartWrapper starts the Lambda runtime loop with the abstract handler.
 func StartWrapper(handler func(Context) error, options ...start.Option) {
-	Start(func(ctx context.Context, req events.LambdaFunctionURLRequest) (response events.LambdaFunctionURLResponse, err error) {
+	Start(NewHandler(handler), options...)
+}
+
+// StartStreamingWrapper starts the Lambda runtime loop with the abstract handler.
+func StartStreamingWrapper(handler func(Context) error, options ...start.Option) {
+	StartStreaming(NewStreamingHandler(handler), options...)
+}
+
+// NewHandler adapts the abstract handler as a Handler in BUFFERED invoke mode.
+func NewHandler(handler func(Context) error) Handler {
+	return func(ctx context.Context, req events.LambdaFunctionURLRequest) (response events.LambdaFunctionURLResponse, err error) {
 		response = events.LambdaFunctionURLResponse{
 			Headers: map[string]string{},
 			Cookies: make([]string, 0),
 		}
 		c := newContext[events.LambdaFunctionURLResponse](ctx, &req, buffered.Wrap(&response))
-		err = handler(c)
+		if err = handler(c); err == nil {
+			err = c.finalise()
+		}
 		return
-	}, options...)
+	}
 }
 
-// StartStreamingWrapper starts the Lambda runtime loop with the abstract handler.
-func StartStreamingWrapper(handler func(Context) error, options ...start.Option) {
-	StartStreaming(func(ctx context.Context, req events.LambdaFunctionURLRequest) (response *events.LambdaFunctionURLStreamingResponse, err error) {
+// NewStreamingHandler adapts the abstract handler as a StreamingHandler in RESPONSE_STREAM invoke mode.
+func NewStreamingHandler(handler func(Context) error) StreamingHandler {
+	return func(ctx context.Context, req events.LambdaFunctionURLRequest) (response *events.LambdaFunctionURLStreamingResponse, err error) {
 		response = &events.LambdaFunctionURLStreamingResponse{
 			Headers: map[string]string{},
 			Cookies: make([]string, 0),
 		}
 		c := newContext[events.LambdaFunctionURLStreamingResponse](ctx, &req, streaming.Wrap(response))
-		err = handler(c)
+		if err = handler(c); err == nil {
+			err = c.finalise()
+		}
 		return
-	}, options...)
+	}
+}
+
+// finalise applies the opt-in features to the response after the handler has returned successfully.
+//
+// The content-based ETag is computed from the uncompressed body so that it is the same regardless of encoding.
+func (c *baseContext[T]) finalise() error {
+	if err := c.applyContentETag(); err != nil {
+		return err
+	}
+
+	return c.applyCompression()
+}
+
+// internalServerErrorBody mirrors the JSON body produced by [Context.RespondInternalServerError].
+const internalServerErrorBody = `{"status":500,"message":"Internal Server Error"}`
+
+// internalServerError is the response returned upon recovering from a panic in BUFFERED mode.
+func internalServerError() events.LambdaFunctionURLResponse {
+	return events.LambdaFunctionURLResponse{
+		StatusCode: http.StatusInternalServerError,
+		Headers:    map[string]string{"Content-Type": "application/json; charset=utf-8"},
+		Body:       internalServerErrorBody,
+	}
+}
+
+// internalServerErrorStreaming is the response returned upon recovering from a panic in RESPONSE_STREAM mode.
+func internalServerErrorStreaming() *events.LambdaFunctionURLStreamingResponse {
+	return &events.LambdaFunctionURLStreamingResponse{
+		StatusCode: http.StatusInternalServerError,
+		Headers:    map[string]string{"Content-Type": "application/json; charset=utf-8"},
+		Body:       strings.NewReader(internalServerErrorBody),
+	}
 }
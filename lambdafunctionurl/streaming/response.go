@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/lambdafunctionurl/compress"
+	"github.com/nguyengg/golambda/lambdafunctionurl/etag"
 	"io"
 	"net/http"
 	"net/textproto"
@@ -69,3 +71,100 @@ func (r *Response) RespondBody(body io.Reader) error {
 	r.response.Body = body
 	return nil
 }
+
+// Header returns the value of the response header with the given key.
+func (r *Response) Header(key string) string {
+	return r.response.Headers[textproto.CanonicalMIMEHeaderKey(key)]
+}
+
+// ContentETag returns the strong ETag computed from the response body.
+//
+// Because the headers are sent before the body, the body is read into memory while being hashed so that the ETag can
+// be computed before any byte is streamed; the body is then replaced by the buffered content. If the body is larger
+// than maxSize bytes, ok is false and the body continues to stream from where the buffered content ends.
+func (r *Response) ContentETag(maxSize int64) (e etag.ETag, ok bool, err error) {
+	h := etag.NewHasher()
+	if r.response.Body == nil {
+		return h.ETag(), true, nil
+	}
+
+	body := r.response.Body
+	buf := &bytes.Buffer{}
+	n, err := io.Copy(io.MultiWriter(buf, h), io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return e, false, err
+	}
+
+	if n <= maxSize {
+		if closer, ok := body.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		r.response.Body = bytes.NewReader(buf.Bytes())
+		return h.ETag(), true, nil
+	}
+
+	rest := io.MultiReader(bytes.NewReader(buf.Bytes()), body)
+	if closer, ok := body.(io.Closer); ok {
+		r.response.Body = struct {
+			io.Reader
+			io.Closer
+		}{rest, closer}
+	} else {
+		r.response.Body = rest
+	}
+
+	return e, false, nil
+}
+
+// Compress replaces the response body with a reader that compresses the original body as it is being streamed (see
+// compress.NewReader), and removes the "Content-Length" header since the compressed size is not known in advance.
+//
+// Up to minimumSize bytes are read ahead to determine if the body is large enough to be compressed; if not, the body
+// is replaced by the buffered content and ok is false. Otherwise, done is called with the number of uncompressed and
+// compressed bytes once the body has been streamed in full, which is after Compress has returned.
+func (r *Response) Compress(enc compress.Encoder, minimumSize int64, done func(uncompressedSize, compressedSize int64)) (ok bool, err error) {
+	if r.response.Body == nil {
+		return false, nil
+	}
+
+	body := r.response.Body
+	buf := &bytes.Buffer{}
+	n, err := io.Copy(buf, io.LimitReader(body, minimumSize))
+	if err != nil {
+		return false, err
+	}
+
+	if n < minimumSize {
+		if closer, ok := body.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		r.response.Body = bytes.NewReader(buf.Bytes())
+		return false, nil
+	}
+
+	var rest io.Reader = io.MultiReader(bytes.NewReader(buf.Bytes()), body)
+	if closer, ok := body.(io.Closer); ok {
+		rest = struct {
+			io.Reader
+			io.Closer
+		}{rest, closer}
+	}
+
+	if r.response.Body, err = compress.NewReader(rest, enc, done); err != nil {
+		r.response.Body = rest
+		return false, err
+	}
+
+	delete(r.response.Headers, "Content-Length")
+	return true, nil
+}
+
+// ClearBody removes the response body and the "Content-Length" header, closing the original body if it implements
+// io.Closer.
+func (r *Response) ClearBody() {
+	if closer, ok := r.response.Body.(io.Closer); ok {
+		_ = closer.Close()
+	}
+	r.response.Body = nil
+	delete(r.response.Headers, "Content-Length")
+}
@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"github.com/rs/zerolog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Units of EMF metrics. See https://docs.aws.amazon.com/AmazonCloudWatch/latest/APIReference/API_MetricDatum.html.
+const (
+	UnitCount        = "Count"
+	UnitMilliseconds = "Milliseconds"
+	UnitNone         = "None"
+)
+
+// emfMaxMetrics is the maximum number of metrics per directive.
+const emfMaxMetrics = 100
+
+// emfMaxValues is the maximum number of values of a metric.
+const emfMaxValues = 100
+
+// emfCountSuffix is appended to the key of a timing to name the metric that counts its durations.
+const emfCountSuffix = ".count"
+
+// EMF configures SimpleMetrics to write CloudWatch Embedded Metric Format documents instead of its own JSON shape.
+//
+// In EMF mode, the properties, counters, floaters, and timings are all top-level fields, and the "_aws" field instructs
+// CloudWatch to extract the counters, floaters, and timings as metrics in the given namespace without any metric filter.
+// If a property has the same key as a metric, the metric wins. The ReservedKeyTime latency of the invocation is reported
+// in milliseconds.
+//
+// Each timing is reported in milliseconds as an array of per-call values if timing histograms are enabled (see
+// SimpleMetrics.SetTimingHistograms) and the timing has at most 100 durations, so that CloudWatch computes the correct
+// average and percentiles; the values are the midpoints of the histogram buckets. Otherwise, the timing is reported as
+// the mean of its durations. Either way, the number of durations is also reported as the "<key>.count" metric with
+// unit UnitCount, so that the sum is the product of the two.
+//
+// See https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html.
+type EMF struct {
+	// Namespace is the CloudWatch namespace of the metrics.
+	Namespace string
+	// DimensionSets contains the dimension sets of the metrics.
+	//
+	// Each dimension is the key of a property (see Metrics.SetProperty) whose value becomes the dimension value. A
+	// dimension set can be empty to publish the metrics without dimensions.
+	DimensionSets [][]string
+	// Units overrides the unit of specific metrics.
+	//
+	// By default, counters have unit UnitCount, floaters UnitNone, and timings UnitMilliseconds.
+	Units map[string]string
+}
+
+// NewEMF creates an EMF with one dimension set comprising the given dimensions.
+func NewEMF(namespace string, dimensions ...string) *EMF {
+	if dimensions == nil {
+		dimensions = []string{}
+	}
+
+	return &EMF{
+		Namespace:     namespace,
+		DimensionSets: [][]string{dimensions},
+	}
+}
+
+// SetEMF changes Log and LogWithEndTime to write CloudWatch Embedded Metric Format documents. Pass nil to revert to the
+// default JSON shape. Has no effect if a Sink has been set with SetSink; use WriterSink.EMF instead.
+//
+// Returns self for chaining.
+func (m *SimpleMetrics) SetEMF(emf *EMF) *SimpleMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.emf = emf
+	return m
+}
+
+// writeEMF writes the snapshot as a CloudWatch Embedded Metric Format document.
+func writeEMF(logger zerolog.Logger, emf *EMF, s Snapshot) {
+	e := logger.Log().
+		Int64(ReservedKeyStartTime, s.StartTime.UnixMilli()).
+		Str(ReservedKeyEndTime, s.EndTime.Format(http.TimeFormat))
+
+	for k, v := range s.Properties {
+		if _, ok := s.Counters[k]; ok {
+			continue
+		}
+		if _, ok := s.Floaters[k]; ok {
+			continue
+		}
+		if _, ok := s.Timings[k]; ok {
+			continue
+		}
+		logProperty(e, k, v)
+	}
+
+	units := map[string]string{ReservedKeyTime: UnitMilliseconds}
+	e.Float64(ReservedKeyTime, milliseconds(s.Latency()))
+
+	for k, v := range s.Counters {
+		units[k] = UnitCount
+		e.Int64(k, v)
+	}
+	for k, v := range s.Floaters {
+		units[k] = UnitNone
+		e.Float64(k, v)
+	}
+	for k, v := range s.Timings {
+		units[k] = UnitMilliseconds
+		if v.Histogram != nil && v.N <= emfMaxValues {
+			e.Floats64(k, emfValues(v.Histogram))
+		} else if v.N != 0 {
+			e.Float64(k, milliseconds(v.Avg()))
+		} else {
+			e.Float64(k, 0)
+		}
+
+		if _, ok := s.Counters[k+emfCountSuffix]; !ok {
+			units[k+emfCountSuffix] = UnitCount
+			e.Int64(k+emfCountSuffix, v.N)
+		}
+	}
+
+	dimensionSets := emf.DimensionSets
+	if len(dimensionSets) == 0 {
+		dimensionSets = [][]string{{}}
+	}
+
+	metadata := emfMetadata{Timestamp: s.EndTime.UnixMilli()}
+	for chunk := range slices.Chunk(sortedKeys(units), emfMaxMetrics) {
+		directive := emfDirective{Namespace: emf.Namespace, Dimensions: dimensionSets}
+		for _, k := range chunk {
+			unit := units[k]
+			if u, ok := emf.Units[k]; ok {
+				unit = u
+			}
+			directive.Metrics = append(directive.Metrics, emfMetricDefinition{Name: k, Unit: unit})
+		}
+		metadata.CloudWatchMetrics = append(metadata.CloudWatchMetrics, directive)
+	}
+
+	e.Interface("_aws", metadata)
+	e.Send()
+}
+
+type emfMetadata struct {
+	Timestamp         int64
+	CloudWatchMetrics []emfDirective
+}
+
+type emfDirective struct {
+	Namespace  string
+	Dimensions [][]string
+	Metrics    []emfMetricDefinition
+}
+
+type emfMetricDefinition struct {
+	Name string
+	Unit string
+}
+
+// emfValues returns the durations recorded in the histogram in milliseconds.
+func emfValues(h *Histogram) []float64 {
+	values := make([]float64, 0, h.N())
+	for v, c := range h.Buckets() {
+		for range c {
+			values = append(values, milliseconds(v))
+		}
+	}
+
+	return values
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSimpleMetrics_SetEMF(t *testing.T) {
+	var buf bytes.Buffer
+	startTime := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	emf := NewEMF("MyNamespace", "functionName")
+	emf.Units = map[string]string{"bytes": "Bytes"}
+
+	m := NewWithStartTime(startTime).(*SimpleMetrics).SetOutput(&buf).SetEMF(emf)
+	m.SetProperty("functionName", "my-function").
+		SetProperty("fault", "shadowed by counter").
+		IncrementCount("requests").
+		SetFloat("bytes", 1024).
+		AddTiming("latency", 2*time.Millisecond).
+		AddTiming("latency", 3*time.Millisecond)
+	m.LogWithEndTime(startTime.Add(time.Second))
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("LogWithEndTime() is not JSON: %v: %s", err, buf.String())
+	}
+
+	for k, want := range map[string]interface{}{
+		"functionName":  "my-function",
+		"fault":         0.0,
+		"panicked":      0.0,
+		"requests":      1.0,
+		"bytes":         1024.0,
+		"latency":       2.5,
+		"latency.count": 2.0,
+		"time":          1000.0,
+	} {
+		if got[k] != want {
+			t.Errorf("LogWithEndTime() %s = %v, want %v", k, got[k], want)
+		}
+	}
+
+	want := map[string]interface{}{
+		"Timestamp": float64(startTime.Add(time.Second).UnixMilli()),
+		"CloudWatchMetrics": []interface{}{
+			map[string]interface{}{
+				"Namespace":  "MyNamespace",
+				"Dimensions": []interface{}{[]interface{}{"functionName"}},
+				"Metrics": []interface{}{
+					map[string]interface{}{"Name": "bytes", "Unit": "Bytes"},
+					map[string]interface{}{"Name": "fault", "Unit": "Count"},
+					map[string]interface{}{"Name": "latency", "Unit": "Milliseconds"},
+					map[string]interface{}{"Name": "latency.count", "Unit": "Count"},
+					map[string]interface{}{"Name": "panicked", "Unit": "Count"},
+					map[string]interface{}{"Name": "requests", "Unit": "Count"},
+					map[string]interface{}{"Name": "time", "Unit": "Milliseconds"},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got["_aws"], want) {
+		t.Errorf("LogWithEndTime() _aws = %#v, want %#v", got["_aws"], want)
+	}
+}
+
+func TestSimpleMetrics_SetEMF_histogram(t *testing.T) {
+	var buf bytes.Buffer
+	startTime := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	m := NewWithStartTime(startTime).(*SimpleMetrics).SetOutput(&buf).SetEMF(NewEMF("MyNamespace")).SetTimingHistograms(true)
+	m.AddTiming("latency", 3*time.Millisecond).
+		AddTiming("latency", 2*time.Millisecond).
+		AddTiming("latency", 2*time.Millisecond)
+	for range emfMaxValues + 1 {
+		m.AddTiming("many", time.Millisecond)
+	}
+	m.LogWithEndTime(startTime.Add(time.Second))
+
+	var got struct {
+		Latency      []float64 `json:"latency"`
+		LatencyCount int64     `json:"latency.count"`
+		Many         float64   `json:"many"`
+		ManyCount    int64     `json:"many.count"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("LogWithEndTime() is not JSON: %v: %s", err, buf.String())
+	}
+
+	if want := []float64{2, 2, 3}; len(got.Latency) != len(want) {
+		t.Errorf("LogWithEndTime() latency = %v, want %v", got.Latency, want)
+	} else {
+		for i := range want {
+			if relErr := math.Abs(got.Latency[i]-want[i]) / want[i]; relErr > 1.0/32 {
+				t.Errorf("LogWithEndTime() latency = %v, want %v within 3%%", got.Latency, want)
+				break
+			}
+		}
+	}
+	if got.LatencyCount != 3 {
+		t.Errorf("LogWithEndTime() latency.count = %d, want 3", got.LatencyCount)
+	}
+
+	// too many durations must fall back to the mean.
+	if got.Many != 1 || got.ManyCount != emfMaxValues+1 {
+		t.Errorf("LogWithEndTime() many = %v, many.count = %d, want 1 and %d", got.Many, got.ManyCount, emfMaxValues+1)
+	}
+}
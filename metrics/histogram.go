@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"iter"
+	"math"
+	"math/bits"
+	"slices"
+	"time"
+)
+
+// histogramSubBucketBits controls the precision of Histogram: every power-of-2 range is divided into 2^5 = 32 linear
+// sub-buckets, so the relative error of a percentile is at most 1/32 (about 3%).
+const histogramSubBucketBits = 5
+
+// Histogram records durations in fixed log-linear buckets to estimate percentiles.
+//
+// Histograms with the same bucket layout (which is every Histogram) can be merged losslessly, so the percentiles of
+// merged histograms are as accurate as if all durations had been added to the same histogram. The zero value is ready
+// for use. Histogram is not thread-safe; SimpleMetrics guards its histograms with its own mutex.
+type Histogram struct {
+	counts map[int]int64
+	n      int64
+}
+
+// NewHistogram creates a new empty Histogram.
+func NewHistogram() *Histogram {
+	return &Histogram{counts: make(map[int]int64)}
+}
+
+// Add records a duration. Negative durations are recorded as zero.
+func (h *Histogram) Add(duration time.Duration) *Histogram {
+	if h.counts == nil {
+		h.counts = make(map[int]int64)
+	}
+
+	h.counts[bucketIndex(duration)]++
+	h.n++
+	return h
+}
+
+// Merge adds all durations recorded by the other histogram to this one.
+func (h *Histogram) Merge(other *Histogram) *Histogram {
+	if other == nil {
+		return h
+	}
+
+	if h.counts == nil {
+		h.counts = make(map[int]int64, len(other.counts))
+	}
+
+	for i, c := range other.counts {
+		h.counts[i] += c
+	}
+	h.n += other.n
+	return h
+}
+
+// Clone returns a deep copy of the histogram.
+func (h *Histogram) Clone() *Histogram {
+	if h == nil {
+		return nil
+	}
+
+	return NewHistogram().Merge(h)
+}
+
+// N returns the number of recorded durations.
+func (h *Histogram) N() int64 {
+	return h.n
+}
+
+// Percentile returns the estimated duration at the given percentile (between 0 and 100).
+//
+// Returns 0 if the histogram is empty.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h == nil || h.n == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(p / 100 * float64(h.n)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	var seen int64
+	var last time.Duration
+	for v, c := range h.Buckets() {
+		if seen += c; seen >= rank {
+			return v
+		}
+		last = v
+	}
+
+	return last
+}
+
+// Buckets returns the non-empty buckets in ascending order, each as the midpoint of the bucket and the number of
+// durations recorded in it.
+//
+// The midpoints approximate the recorded durations with the same precision as Percentile.
+func (h *Histogram) Buckets() iter.Seq2[time.Duration, int64] {
+	return func(yield func(time.Duration, int64) bool) {
+		if h == nil {
+			return
+		}
+
+		indices := make([]int, 0, len(h.counts))
+		for i := range h.counts {
+			indices = append(indices, i)
+		}
+		slices.Sort(indices)
+
+		for _, i := range indices {
+			if !yield(bucketValue(i), h.counts[i]) {
+				return
+			}
+		}
+	}
+}
+
+// bucketIndex returns the index of the bucket that contains the duration.
+//
+// Durations less than 2^histogramSubBucketBits nanoseconds have their own buckets. Larger durations are shifted right
+// so that only the histogramSubBucketBits+1 most significant bits remain, and the index is made up of the shift
+// amount and those bits.
+func bucketIndex(duration time.Duration) int {
+	if duration < 0 {
+		return 0
+	}
+
+	v := uint64(duration)
+	if v < 1<<histogramSubBucketBits {
+		return int(v)
+	}
+
+	shift := bits.Len64(v) - histogramSubBucketBits - 1
+	return (shift+1)<<histogramSubBucketBits + int(v>>shift) - 1<<histogramSubBucketBits
+}
+
+// bucketValue returns the midpoint of the bucket at the given index.
+func bucketValue(index int) time.Duration {
+	if index < 1<<histogramSubBucketBits {
+		return time.Duration(index)
+	}
+
+	shift := index>>histogramSubBucketBits - 1
+	lower := uint64(index&(1<<histogramSubBucketBits-1)+1<<histogramSubBucketBits) << shift
+	return time.Duration(lower + (uint64(1)<<shift)/2)
+}
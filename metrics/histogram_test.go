@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHistogram_Percentile(t *testing.T) {
+	h := NewHistogram()
+	for i := 1; i <= 1000; i++ {
+		h.Add(time.Duration(i) * time.Millisecond)
+	}
+
+	for _, tt := range []struct {
+		p    float64
+		want time.Duration
+	}{
+		{p: 50, want: 500 * time.Millisecond},
+		{p: 90, want: 900 * time.Millisecond},
+		{p: 99, want: 990 * time.Millisecond},
+		{p: 100, want: 1000 * time.Millisecond},
+	} {
+		got := h.Percentile(tt.p)
+		if relErr := math.Abs(float64(got-tt.want)) / float64(tt.want); relErr > 1.0/32 {
+			t.Errorf("Percentile(%v) = %v, want %v within 3%%", tt.p, got, tt.want)
+		}
+	}
+
+	if got := NewHistogram().Percentile(50); got != 0 {
+		t.Errorf("Percentile() of empty histogram = %v, want 0", got)
+	}
+}
+
+func TestHistogram_bucketIndex(t *testing.T) {
+	for _, d := range []time.Duration{0, 1, 31, 32, 33, 63, 64, 65, time.Millisecond, time.Hour} {
+		v := bucketValue(bucketIndex(d))
+		if relErr := math.Abs(float64(v-d)) / math.Max(float64(d), 1); relErr > 1.0/32 {
+			t.Errorf("bucketValue(bucketIndex(%d)) = %d", d, v)
+		}
+	}
+}
+
+func TestSimpleMetrics_Merge(t *testing.T) {
+	a := NewWithStartTime(time.Now()).(*SimpleMetrics).SetTimingHistograms(true)
+	b := NewWithStartTime(time.Now()).(*SimpleMetrics).SetTimingHistograms(true)
+	for i := 1; i <= 50; i++ {
+		a.AddTiming("latency", time.Duration(i)*time.Millisecond)
+		b.AddTiming("latency", time.Duration(i+50)*time.Millisecond)
+	}
+	a.IncrementCount("requests")
+	b.IncrementCount("requests").AddFloat("bytes", 1.5)
+
+	var buf bytes.Buffer
+	a.Merge(b).SetOutput(&buf).Log()
+
+	var got struct {
+		Counters map[string]int64
+		Floaters map[string]float64
+		Timings  map[string]map[string]interface{}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Counters["requests"] != 2 || got.Floaters["bytes"] != 1.5 {
+		t.Errorf("Merge() counters = %v, floaters = %v", got.Counters, got.Floaters)
+	}
+	if latency := got.Timings["latency"]; latency["n"] != 100.0 || latency["min"] != "1.000 ms" || latency["max"] != "100.000 ms" {
+		t.Errorf("Merge() latency = %v", latency)
+	}
+	if p := a.timings["latency"].Histogram.Percentile(99); p < 97*time.Millisecond || p > 102*time.Millisecond {
+		t.Errorf("Merge() p99 = %v, want ~99ms", p)
+	}
+	if _, ok := got.Timings["latency"]["p99"]; !ok {
+		t.Errorf("Log() does not include p99: %s", buf.String())
+	}
+}
@@ -85,6 +85,17 @@ type Metrics interface {
 	Log()
 	// LogWithEndTime is a variant of Log that receives an explicit end time.
 	LogWithEndTime(time.Time)
+
+	// Child creates a scoped Metrics for a sub-operation, such as a single record of an SQS batch.
+	//
+	// The child starts at the current time and copies the "requestId" and "lambdaRequestId" properties from its
+	// parent so that their log lines can be joined, and adds a PropertyKeyScope property whose value is the dotted
+	// names of all its ancestor children (e.g. "record.s3"). When the child is logged, it writes its own line, then
+	// its counters, floaters, and timings are rolled up into the parent with the name and a "." as key prefix, and its
+	// latency is added to the parent's timing of the same name.
+	//
+	// A child must be logged at most once, and before its parent is logged; otherwise its metrics are not rolled up.
+	Child(name string) Metrics
 }
 
 // Default counter metrics that are always emitted.
@@ -93,6 +104,9 @@ const (
 	CounterKeyPanicked = "panicked"
 )
 
+// PropertyKeyScope is the property that identifies a child Metrics created by Metrics.Child.
+const PropertyKeyScope = "scope"
+
 // Reserved property keys.
 const (
 	ReservedKeyStartTime = "startTime"
@@ -2,7 +2,10 @@ package metrics
 
 import (
 	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	awsmw "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/smithy-go"
 	smithymw "github.com/aws/smithy-go/middleware"
@@ -30,23 +33,106 @@ func Default(o *config.LoadOptions) error {
 //	cfg, _ := config.LoadDefaultConfig(ctx)
 //	cfg.APIOptions = append(cfg.APIOptions, metrics.ClientSideMetricsMiddleware())
 //
-// A metrics.Metrics instance must be available from context by the time the middleware receives a response. At the
-// moment, there are no customizations available yet.
+// A metrics.Metrics instance must be available from context by the time the middleware receives a response. By
+// default, every attempt is logged as its own zerolog line, and its latency and faults are aggregated into the metrics
+// instance under the key "{serviceId}.{operationName}" (e.g. "DynamoDB.GetItem"). Use the Option functions such as
+// WithoutLogging, WithKeyPrefix, WithRetryMetrics, and WithByteSizeMetrics to customise this behaviour.
 func ClientSideMetricsMiddleware(options ...Option) func(stack *smithymw.Stack) error {
+	c := &clientSideMetricsMiddleware{}
+	for _, opt := range options {
+		opt(c)
+	}
+
 	return func(stack *smithymw.Stack) error {
-		return stack.Deserialize.Add(&clientSideMetricsMiddleware{}, smithymw.After)
+		if err := stack.Deserialize.Add(c, smithymw.After); err != nil {
+			return err
+		}
+
+		if c.retries {
+			// the Initialize step wraps the retry loop so the attempt results are available on the way out.
+			return stack.Initialize.Add(&clientSideRetryMetricsMiddleware{c}, smithymw.After)
+		}
+
+		return nil
 	}
 }
 
 // Should implement middleware.DeserializeMiddleware.
 type clientSideMetricsMiddleware struct {
+	disableLogging bool
+	keyFn          func(serviceId, operationName string) string
+	retries        bool
+	byteSizes      bool
 }
 
 // Option allows customization of the ClientSideMetricsMiddleware.
-//
-// At the moment, there are no customizations available yet.
 type Option func(*clientSideMetricsMiddleware)
 
+// WithoutLogging disables the zerolog line that is emitted for every attempt, and the standard log line that
+// logsupport.LogSmithyError emits for every failed attempt.
+//
+// Metrics are still aggregated into the metrics.Metrics instance from context.
+func WithoutLogging() Option {
+	return func(c *clientSideMetricsMiddleware) {
+		c.disableLogging = true
+	}
+}
+
+// WithKeyPrefix adds a prefix to every metric key, e.g. "aws." turns "DynamoDB.GetItem" into "aws.DynamoDB.GetItem".
+//
+// Overrides any previous WithKeyFunc or WithKeyPrefix.
+func WithKeyPrefix(prefix string) Option {
+	return func(c *clientSideMetricsMiddleware) {
+		c.keyFn = func(serviceId, operationName string) string {
+			return prefix + serviceId + "." + operationName
+		}
+	}
+}
+
+// WithKeyFunc replaces the default "{serviceId}.{operationName}" metric key with the result of the given function.
+//
+// The suffixes such as ".ClientFault" or ".Retries" are still appended to the returned key.
+//
+// Overrides any previous WithKeyFunc or WithKeyPrefix.
+func WithKeyFunc(fn func(serviceId, operationName string) string) Option {
+	return func(c *clientSideMetricsMiddleware) {
+		c.keyFn = fn
+	}
+}
+
+// WithRetryMetrics adds counters "{key}.Attempts", "{key}.Retries", and "{key}.Throttles" from the retry metadata of
+// each call.
+//
+// Throttles are attempts whose errors are considered throttling errors by retry.DefaultThrottles.
+func WithRetryMetrics() Option {
+	return func(c *clientSideMetricsMiddleware) {
+		c.retries = true
+	}
+}
+
+// WithByteSizeMetrics adds counters "{key}.RequestBytes" and "{key}.ResponseBytes" from the length of the request and
+// response bodies of every attempt.
+//
+// Bodies whose length is unknown (e.g. streaming) are not counted.
+func WithByteSizeMetrics() Option {
+	return func(c *clientSideMetricsMiddleware) {
+		c.byteSizes = true
+	}
+}
+
+func (c clientSideMetricsMiddleware) key(ctx context.Context) string {
+	serviceId := awsmw.GetServiceID(ctx)
+	operationName := awsmw.GetOperationName(ctx)
+	if c.keyFn != nil {
+		return c.keyFn(serviceId, operationName)
+	}
+
+	// DynamoDB GetItem => DynamoDB.GetItem
+	// log filter can use { $.['DynamoDB.GetItem.Fault'] = * }
+	// see https://docs.aws.amazon.com/AmazonCloudWatch/latest/logs/FilterAndPatternSyntax.html#matching-terms-json-log-events
+	return serviceId + "." + operationName
+}
+
 func (c clientSideMetricsMiddleware) ID() string {
 	return "ClientSideLatencyMetrics"
 }
@@ -65,6 +151,11 @@ func (c clientSideMetricsMiddleware) HandleDeserialize(ctx context.Context, inpu
 	operationName := awsmw.GetOperationName(ctx)
 
 	logContext := zerolog.Ctx(ctx)
+	if c.disableLogging {
+		nop := zerolog.Nop()
+		logContext = &nop
+	}
+
 	logger := logContext.
 		Log().
 		Str("service", serviceId).
@@ -91,16 +182,32 @@ func (c clientSideMetricsMiddleware) HandleDeserialize(ctx context.Context, inpu
 		}
 	}
 
-	// DynamoDB GetItem => DynamoDB.GetItem
-	// log filter can use { $.['DynamoDB.GetItem.Fault'] = * }
-	// see https://docs.aws.amazon.com/AmazonCloudWatch/latest/logs/FilterAndPatternSyntax.html#matching-terms-json-log-events
-	key := serviceId + "." + operationName
+	key := c.key(ctx)
 
 	m := Ctx(ctx)
 	m.AddTiming(key, end.Sub(start))
 
+	if c.byteSizes {
+		if req, ok := input.Request.(*smithyhttp.Request); ok && req.ContentLength >= 0 {
+			m.AddCount(key+".RequestBytes", req.ContentLength)
+			logger.Int64("requestBytes", req.ContentLength)
+		}
+		if resp, ok := output.RawResponse.(*smithyhttp.Response); ok && resp.ContentLength >= 0 {
+			m.AddCount(key+".ResponseBytes", resp.ContentLength)
+			logger.Int64("responseBytes", resp.ContentLength)
+		}
+	}
+
 	if err != nil {
-		_, _, _, _, fault := logsupport.LogSmithyError(err)
+		var fault smithy.ErrorFault
+		if c.disableLogging {
+			var ae smithy.APIError
+			if errors.As(err, &ae) {
+				fault = ae.ErrorFault()
+			}
+		} else {
+			_, _, _, _, fault = logsupport.LogSmithyError(err)
+		}
 
 		switch fault {
 		case smithy.FaultClient:
@@ -125,3 +232,39 @@ func (c clientSideMetricsMiddleware) HandleDeserialize(ctx context.Context, inpu
 
 	return output, metadata, err
 }
+
+// Should implement middleware.InitializeMiddleware.
+//
+// The retry middleware lives in the Finalize step and records its attempt results into the metadata, so this must be
+// added before it to see all attempts of a call.
+type clientSideRetryMetricsMiddleware struct {
+	c *clientSideMetricsMiddleware
+}
+
+func (r clientSideRetryMetricsMiddleware) ID() string {
+	return "ClientSideRetryMetrics"
+}
+
+func (r clientSideRetryMetricsMiddleware) HandleInitialize(ctx context.Context, input smithymw.InitializeInput, handler smithymw.InitializeHandler) (smithymw.InitializeOutput, smithymw.Metadata, error) {
+	output, metadata, err := handler.HandleInitialize(ctx, input)
+
+	results, ok := retry.GetAttemptResults(metadata)
+	if !ok || len(results.Results) == 0 {
+		return output, metadata, err
+	}
+
+	var throttles int64
+	for _, result := range results.Results {
+		if result.Err != nil && retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(result.Err) == aws.TrueTernary {
+			throttles++
+		}
+	}
+
+	key := r.c.key(ctx)
+	Ctx(ctx).
+		AddCount(key+".Attempts", int64(len(results.Results))).
+		AddCount(key+".Retries", int64(len(results.Results)-1)).
+		AddCount(key+".Throttles", throttles)
+
+	return output, metadata, err
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/rs/zerolog"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestClientSideMetricsMiddleware(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		if attempts++; attempts < 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ThrottlingException","message":"slow down"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := dynamodb.New(dynamodb.Options{
+		Region:       "us-west-2",
+		Credentials:  aws.AnonymousCredentials{},
+		BaseEndpoint: aws.String(server.URL),
+		Retryer: retry.NewStandard(func(o *retry.StandardOptions) {
+			o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+			o.RateLimiter = ratelimit.None
+		}),
+		APIOptions: []func(*middleware.Stack) error{
+			ClientSideMetricsMiddleware(WithoutLogging(), WithKeyPrefix("aws."), WithRetryMetrics(), WithByteSizeMetrics()),
+		},
+	})
+
+	var logs bytes.Buffer
+	ctx := zerolog.New(&logs).WithContext(context.Background())
+
+	var stdLogs bytes.Buffer
+	log.SetOutput(&stdLogs)
+	defer log.SetOutput(os.Stderr)
+
+	var buf bytes.Buffer
+	m := NewWithStartTime(time.Now()).(*SimpleMetrics).SetOutput(&buf)
+	if _, err := client.DescribeTable(m.WithContext(ctx), &dynamodb.DescribeTableInput{TableName: aws.String("my-table")}); err != nil {
+		t.Fatalf("DescribeTable() error = %v", err)
+	}
+	m.Log()
+
+	if logs.Len() != 0 {
+		t.Errorf("WithoutLogging() still logged: %s", logs.String())
+	}
+	if stdLogs.Len() != 0 {
+		t.Errorf("WithoutLogging() still logged to log.Default(): %s", stdLogs.String())
+	}
+
+	var got struct {
+		Counters map[string]int64
+		Timings  map[string]json.RawMessage
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Log() is not JSON: %v: %s", err, buf.String())
+	}
+
+	for k, want := range map[string]int64{
+		"aws.DynamoDB.DescribeTable.Attempts":      3,
+		"aws.DynamoDB.DescribeTable.Retries":       2,
+		"aws.DynamoDB.DescribeTable.Throttles":     2,
+		"aws.DynamoDB.DescribeTable.RequestBytes":  3 * int64(len(`{"TableName":"my-table"}`)),
+		"aws.DynamoDB.DescribeTable.ResponseBytes": 2*int64(len(`{"__type":"com.amazonaws.dynamodb.v20120810#ThrottlingException","message":"slow down"}`)) + 2,
+	} {
+		if got.Counters[k] != want {
+			t.Errorf("counters[%s] = %d, want %d", k, got.Counters[k], want)
+		}
+	}
+
+	if _, ok := got.Timings["aws.DynamoDB.DescribeTable"]; !ok {
+		t.Errorf("timings is missing aws.DynamoDB.DescribeTable: %s", buf.String())
+	}
+}
@@ -91,3 +91,7 @@ func (m *NullMetrics) LogWithEndTime(time.Time) {
 	logger := zerolog.New(os.Stderr)
 	logger.Log().Int("nullMetrics", 1).Send()
 }
+
+func (m *NullMetrics) Child(string) Metrics {
+	return m
+}
@@ -0,0 +1,154 @@
+// Package otelmetrics bridges metrics.SimpleMetrics to OpenTelemetry metrics.
+//
+// Usage:
+//
+//	sqsevent.Start(handler, start.WithMetricsSink(otelmetrics.New(otel.Meter("my-function"), "functionName")))
+package otelmetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nguyengg/golambda/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"sync"
+	"time"
+)
+
+// Sink is a metrics.Sink that records the metrics with OpenTelemetry instruments created from a metric.Meter.
+//
+// Counters are added to an Int64Counter, floaters are recorded in a Float64Histogram with no unit, and timings are
+// recorded in a Float64Histogram with unit "ms". Each of the metrics.TimingStats.Samples of a timing is recorded as
+// many times as its count so that the histogram has the correct count, sum, and distribution. The
+// metrics.ReservedKeyTime latency of the invocation is also recorded as a timing. Properties are not recorded unless
+// their keys are given to New, in which case they become attributes of every measurement.
+//
+// Instruments are created on first use and cached for the lifetime of the Sink.
+type Sink struct {
+	meter      metric.Meter
+	attributes []string
+
+	counters   map[string]metric.Int64Counter
+	histograms map[string]metric.Float64Histogram
+	mu         sync.Mutex
+}
+
+var _ metrics.Sink = &Sink{}
+
+// New creates a Sink that records metrics with instruments from the given meter.
+//
+// The values of the properties with the given keys are added as attributes to every measurement.
+func New(meter metric.Meter, attributes ...string) *Sink {
+	return &Sink{
+		meter:      meter,
+		attributes: attributes,
+		counters:   map[string]metric.Int64Counter{},
+		histograms: map[string]metric.Float64Histogram{},
+	}
+}
+
+func (s *Sink) Write(snapshot metrics.Snapshot) error {
+	ctx := context.Background()
+
+	attrs := make([]attribute.KeyValue, 0, len(s.attributes))
+	for _, k := range s.attributes {
+		switch v := snapshot.Properties[k].(type) {
+		case nil:
+		case string:
+			attrs = append(attrs, attribute.String(k, v))
+		case int64:
+			attrs = append(attrs, attribute.Int64(k, v))
+		case float64:
+			attrs = append(attrs, attribute.Float64(k, v))
+		default:
+			attrs = append(attrs, attribute.String(k, fmt.Sprint(v)))
+		}
+	}
+	opt := metric.WithAttributes(attrs...)
+
+	var errs []error
+
+	if h, err := s.histogram(metrics.ReservedKeyTime, "ms"); err != nil {
+		errs = append(errs, err)
+	} else {
+		h.Record(ctx, milliseconds(snapshot.Latency()), opt)
+	}
+
+	for k, v := range snapshot.Counters {
+		c, err := s.counter(k)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c.Add(ctx, v, opt)
+	}
+
+	for k, v := range snapshot.Floaters {
+		h, err := s.histogram(k, "")
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		h.Record(ctx, v, opt)
+	}
+
+	for k, v := range snapshot.Timings {
+		h, err := s.histogram(k, "ms")
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for d, n := range v.Samples() {
+			for range n {
+				h.Record(ctx, milliseconds(d), opt)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Sink) counter(name string) (metric.Int64Counter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.counters[name]; ok {
+		return c, nil
+	}
+
+	c, err := s.meter.Int64Counter(name)
+	if err != nil {
+		return nil, fmt.Errorf("create counter %q error: %w", name, err)
+	}
+
+	s.counters[name] = c
+	return c, nil
+}
+
+// histogram caches by name only so a floater and a timing of the same name share the first unit.
+func (s *Sink) histogram(name, unit string) (metric.Float64Histogram, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if h, ok := s.histograms[name]; ok {
+		return h, nil
+	}
+
+	var opts []metric.Float64HistogramOption
+	if unit != "" {
+		opts = append(opts, metric.WithUnit(unit))
+	}
+
+	h, err := s.meter.Float64Histogram(name, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create histogram %q error: %w", name, err)
+	}
+
+	s.histograms[name] = h
+	return h, nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,117 @@
+package otelmetrics
+
+import (
+	"context"
+	"github.com/nguyengg/golambda/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSink(t *testing.T) {
+	meter := &recordingMeter{
+		created:      map[string]int{},
+		counters:     map[string]int64{},
+		histograms:   map[string][]float64{},
+		units:        map[string]string{},
+		functionName: map[string]string{},
+	}
+	r := &metrics.Recorder{}
+	sink := New(meter, "functionName")
+
+	for i := 0; i < 2; i++ {
+		m := metrics.New().(*metrics.SimpleMetrics).SetSink(r)
+		m.SetProperty("functionName", "my-function").
+			IncrementCount("requests").
+			SetFloat("bytes", 1024).
+			AddTiming("latency", 2*time.Millisecond).
+			AddTiming("latency", 3*time.Millisecond).
+			Log()
+
+		s, _ := r.Last()
+		if err := sink.Write(s); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	for k, want := range map[string]int64{"requests": 2, metrics.CounterKeyFault: 0} {
+		if got := meter.counters[k]; got != want {
+			t.Errorf("counter %s = %d, want %d", k, got, want)
+		}
+	}
+	if got := meter.histograms["bytes"]; len(got) != 2 || got[0] != 1024 {
+		t.Errorf("histogram bytes = %v, want [1024 1024]", got)
+	}
+	if got := meter.histograms["latency"]; !slices.Equal(got, []float64{2.5, 2.5, 2.5, 2.5}) {
+		t.Errorf("histogram latency = %v, want [2.5 2.5 2.5 2.5]", got)
+	}
+	if got := len(meter.histograms[metrics.ReservedKeyTime]); got != 2 {
+		t.Errorf("len(histogram time) = %d, want 2", got)
+	}
+	for k, want := range map[string]string{"bytes": "", "latency": "ms", metrics.ReservedKeyTime: "ms"} {
+		if got := meter.units[k]; got != want {
+			t.Errorf("unit of %s = %q, want %q", k, got, want)
+		}
+	}
+	for k, n := range meter.created {
+		if n != 1 {
+			t.Errorf("instrument %s created %d times, want 1", k, n)
+		}
+	}
+	for k, v := range meter.functionName {
+		if v != "my-function" {
+			t.Errorf("attribute functionName of %s = %q, want my-function", k, v)
+		}
+	}
+}
+
+type recordingMeter struct {
+	noop.Meter
+	created      map[string]int
+	counters     map[string]int64
+	histograms   map[string][]float64
+	units        map[string]string
+	functionName map[string]string
+}
+
+func (m *recordingMeter) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	m.created[name]++
+	return &recordingCounter{m: m, name: name}, nil
+}
+
+func (m *recordingMeter) Float64Histogram(name string, options ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	m.created[name]++
+	m.units[name] = metric.NewFloat64HistogramConfig(options...).Unit()
+	return &recordingHistogram{m: m, name: name}, nil
+}
+
+func (m *recordingMeter) attributes(name string, set attribute.Set) {
+	if v, ok := set.Value("functionName"); ok {
+		m.functionName[name] = v.AsString()
+	}
+}
+
+type recordingCounter struct {
+	noop.Int64Counter
+	m    *recordingMeter
+	name string
+}
+
+func (c *recordingCounter) Add(_ context.Context, incr int64, options ...metric.AddOption) {
+	c.m.counters[c.name] += incr
+	c.m.attributes(c.name, metric.NewAddConfig(options).Attributes())
+}
+
+type recordingHistogram struct {
+	noop.Float64Histogram
+	m    *recordingMeter
+	name string
+}
+
+func (h *recordingHistogram) Record(_ context.Context, incr float64, options ...metric.RecordOption) {
+	h.m.histograms[h.name] = append(h.m.histograms[h.name], incr)
+	h.m.attributes(h.name, metric.NewRecordConfig(options).Attributes())
+}
@@ -2,21 +2,29 @@ package metrics
 
 import (
 	"context"
-	"github.com/rs/zerolog"
-	"net/http"
-	"os"
+	"io"
+	"log"
 	"sync"
 	"time"
 )
 
 // SimpleMetrics is thread-safe by use of mutex.
 type SimpleMetrics struct {
-	properties map[string]pvType
+	properties map[string]interface{}
 	counters   map[string]int64
 	floaters   map[string]float64
 	timings    map[string]TimingStats
 	startTime  time.Time
+	out        io.Writer
+	emf        *EMF
+	sink       Sink
+	histograms bool
 	mu         sync.Mutex
+
+	// set by Child.
+	parent *SimpleMetrics
+	name   string
+	scope  string
 }
 
 var _ Metrics = &SimpleMetrics{}
@@ -30,7 +38,7 @@ func New() Metrics {
 // NewWithStartTime is a variant of New that allows caller to override the startTime property.
 func NewWithStartTime(startTime time.Time) Metrics {
 	return &SimpleMetrics{
-		properties: map[string]pvType{},
+		properties: map[string]interface{}{},
 		counters: map[string]int64{
 			CounterKeyFault:    0,
 			CounterKeyPanicked: 0,
@@ -41,6 +49,104 @@ func NewWithStartTime(startTime time.Time) Metrics {
 	}
 }
 
+// SetOutput changes the destination of Log and LogWithEndTime, which is os.Stderr by default. Has no effect if a Sink has
+// been set with SetSink; use WriterSink.Writer instead.
+//
+// Returns self for chaining.
+func (m *SimpleMetrics) SetOutput(w io.Writer) *SimpleMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.out = w
+	return m
+}
+
+// SetSink changes Log and LogWithEndTime to pass the metrics to the given Sink instead of writing them to the output
+// configured by SetOutput and SetEMF. Pass nil to revert to the output.
+//
+// Returns self for chaining.
+func (m *SimpleMetrics) SetSink(sink Sink) *SimpleMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sink = sink
+	return m
+}
+
+// SetTimingHistograms enables or disables recording timings in a Histogram so that their p50, p90, and p99 percentiles
+// are also logged. Only timings created after the invocation are affected.
+//
+// Returns self for chaining.
+func (m *SimpleMetrics) SetTimingHistograms(enabled bool) *SimpleMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.histograms = enabled
+	return m
+}
+
+// Merge adds the counters, floaters, and timings of the other SimpleMetrics to this one.
+//
+// Properties are not merged. Timings are merged with TimingStats.Merge so their percentiles are preserved if both
+// SimpleMetrics have timing histograms enabled.
+//
+// Returns self for chaining.
+func (m *SimpleMetrics) Merge(other *SimpleMetrics) *SimpleMetrics {
+	return m.merge(other, "")
+}
+
+// merge is Merge with the given prefix added to every key from other.
+func (m *SimpleMetrics) merge(other *SimpleMetrics, prefix string) *SimpleMetrics {
+	other.mu.Lock()
+	counters := make(map[string]int64, len(other.counters))
+	for k, v := range other.counters {
+		counters[prefix+k] = v
+	}
+	floaters := make(map[string]float64, len(other.floaters))
+	for k, v := range other.floaters {
+		floaters[prefix+k] = v
+	}
+	timings := make(map[string]TimingStats, len(other.timings))
+	for k, v := range other.timings {
+		v.Histogram = v.Histogram.Clone()
+		timings[prefix+k] = v
+	}
+	other.mu.Unlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.counters == nil {
+		m.counters = make(map[string]int64, len(counters))
+	}
+	for k, v := range counters {
+		m.counters[k] += v
+	}
+
+	if m.floaters == nil {
+		m.floaters = make(map[string]float64, len(floaters))
+	}
+	for k, v := range floaters {
+		m.floaters[k] += v
+	}
+
+	if m.timings == nil {
+		m.timings = make(map[string]TimingStats, len(timings))
+	}
+	for k, v := range timings {
+		stats, ok := m.timings[k]
+		if !ok {
+			m.timings[k] = v
+			continue
+		}
+
+		stats.Merge(v)
+		m.timings[k] = stats
+	}
+
+	return m
+}
+
 func (m *SimpleMetrics) WithContext(ctx context.Context) context.Context {
 	return context.WithValue(ctx, metricsKey{}, m)
 }
@@ -54,11 +160,11 @@ func (m *SimpleMetrics) SetProperty(key, value string) Metrics {
 	}
 
 	if m.properties == nil {
-		m.properties = map[string]pvType{key: strPv{v: value}}
+		m.properties = map[string]interface{}{key: value}
 		return m
 	}
 
-	m.properties[key] = strPv{v: value}
+	m.properties[key] = value
 	return m
 }
 
@@ -71,11 +177,11 @@ func (m *SimpleMetrics) SetInt64Property(key string, value int64) Metrics {
 	}
 
 	if m.properties == nil {
-		m.properties = map[string]pvType{key: intPv{v: value}}
+		m.properties = map[string]interface{}{key: value}
 		return m
 	}
 
-	m.properties[key] = intPv{v: value}
+	m.properties[key] = value
 	return m
 }
 
@@ -88,11 +194,11 @@ func (m *SimpleMetrics) SetFloat64Property(key string, value float64) Metrics {
 	}
 
 	if m.properties == nil {
-		m.properties = map[string]pvType{key: floatPv{v: value}}
+		m.properties = map[string]interface{}{key: value}
 		return m
 	}
 
-	m.properties[key] = floatPv{v: value}
+	m.properties[key] = value
 	return m
 }
 
@@ -105,11 +211,11 @@ func (m *SimpleMetrics) SetJSONProperty(key string, value interface{}) Metrics {
 	}
 
 	if m.properties == nil {
-		m.properties = map[string]pvType{key: interPv{v: value}}
+		m.properties = map[string]interface{}{key: value}
 		return m
 	}
 
-	m.properties[key] = interPv{v: value}
+	m.properties[key] = value
 	return m
 }
 
@@ -244,11 +350,11 @@ func (m *SimpleMetrics) SetTiming(key string, duration time.Duration) Metrics {
 	defer m.mu.Unlock()
 
 	if m.timings == nil {
-		m.timings = map[string]TimingStats{key: NewTimingStats(duration)}
+		m.timings = map[string]TimingStats{key: m.newTimingStats(duration)}
 		return m
 	}
 
-	m.timings[key] = NewTimingStats(duration)
+	m.timings[key] = m.newTimingStats(duration)
 	return m
 }
 
@@ -257,20 +363,30 @@ func (m *SimpleMetrics) AddTiming(key string, delta time.Duration) Metrics {
 	defer m.mu.Unlock()
 
 	if m.timings == nil {
-		m.timings = map[string]TimingStats{key: NewTimingStats(delta)}
+		m.timings = map[string]TimingStats{key: m.newTimingStats(delta)}
+		return m
 	}
 
 	stats, ok := m.timings[key]
 	if !ok {
-		stats = NewTimingStats(delta)
-		m.timings[key] = stats
+		m.timings[key] = m.newTimingStats(delta)
 		return m
 	}
 
 	stats.Add(delta)
+	m.timings[key] = stats
 	return m
 }
 
+// newTimingStats must be called while holding the lock.
+func (m *SimpleMetrics) newTimingStats(duration time.Duration) TimingStats {
+	if m.histograms {
+		return NewTimingStatsWithHistogram(duration)
+	}
+
+	return NewTimingStats(duration)
+}
+
 var statusCodeFlags = []int{StatusCode1xx, StatusCode2xx, StatusCode3xx, StatusCode4xx, StatusCode5xx}
 var statusCodeCounters = []string{"1xx", "2xx", "3xx", "4xx", "5xx"}
 
@@ -283,9 +399,9 @@ func (m *SimpleMetrics) SetStatusCodeWithFlag(statusCode int, flag int) Metrics
 	defer m.mu.Unlock()
 
 	if m.properties == nil {
-		m.properties = map[string]pvType{"statusCode": intPv{v: int64(statusCode)}}
+		m.properties = map[string]interface{}{"statusCode": int64(statusCode)}
 	} else {
-		m.properties["statusCode"] = intPv{v: int64(statusCode)}
+		m.properties["statusCode"] = int64(statusCode)
 	}
 
 	for i, c := range statusCodeCounters {
@@ -299,92 +415,84 @@ func (m *SimpleMetrics) SetStatusCodeWithFlag(statusCode int, flag int) Metrics
 	return m
 }
 
-func (m *SimpleMetrics) Log() {
-	m.LogWithEndTime(time.Now())
-}
-
-func (m *SimpleMetrics) LogWithEndTime(endTime time.Time) {
+func (m *SimpleMetrics) Child(name string) Metrics {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	logger := zerolog.New(os.Stderr)
-	e := logger.Log().
-		Int64(ReservedKeyStartTime, m.startTime.UnixNano()/int64(time.Millisecond)).
-		Str(ReservedKeyEndTime, endTime.Format(http.TimeFormat)).
-		Str(ReservedKeyTime, FormatDuration(endTime.Sub(m.startTime)))
-
-	if len(m.properties) != 0 {
-		for k, v := range m.properties {
-			v.Log(k, e)
-		}
-	}
-
-	if len(m.counters) != 0 {
-		c := zerolog.Dict()
-		for k, v := range m.counters {
-			c.Int64(k, v)
-		}
-		e.Dict(ReservedKeyCounters, c)
+	scope := name
+	if m.scope != "" {
+		scope = m.scope + "." + name
 	}
 
-	if len(m.floaters) != 0 {
-		c := zerolog.Dict()
-		for k, v := range m.floaters {
-			c.Float64(k, v)
+	c := NewWithStartTime(time.Now()).(*SimpleMetrics)
+	c.out = m.out
+	c.emf = m.emf
+	c.sink = m.sink
+	c.histograms = m.histograms
+	c.parent = m
+	c.name = name
+	c.scope = scope
+
+	for _, k := range []string{"requestId", "lambdaRequestId"} {
+		if v, ok := m.properties[k]; ok {
+			c.properties[k] = v
 		}
-		e.Dict(ReservedKeyFloaters, c)
-	}
-
-	if len(m.timings) != 0 {
-		c := zerolog.Dict()
-		for k, v := range m.timings {
-			c.Dict(
-				k,
-				zerolog.Dict().
-					Str("sum", FormatDuration(v.Sum)).
-					Str("min", FormatDuration(v.Min)).
-					Str("max", FormatDuration(v.Max)).
-					Int64("n", v.N).
-					Str("avg", FormatDuration(v.Avg())))
-		}
-		e.Dict(ReservedKeyTimings, c)
 	}
+	c.properties[PropertyKeyScope] = scope
 
-	e.Send()
-}
-
-type pvType interface {
-	Log(string, *zerolog.Event)
+	return c
 }
 
-type strPv struct {
-	v string
+func (m *SimpleMetrics) Log() {
+	m.LogWithEndTime(time.Now())
 }
 
-func (t strPv) Log(key string, e *zerolog.Event) {
-	e.Str(key, t.v)
-}
+func (m *SimpleMetrics) LogWithEndTime(endTime time.Time) {
+	m.log(endTime)
 
-type intPv struct {
-	v int64
+	if m.parent != nil {
+		m.parent.merge(m, m.name+".").AddTiming(m.name, endTime.Sub(m.startTime))
+	}
 }
 
-func (t intPv) Log(key string, e *zerolog.Event) {
-	e.Int64(key, t.v)
+func (m *SimpleMetrics) log(endTime time.Time) {
+	sink, snapshot := m.snapshot(endTime)
+	if err := sink.Write(snapshot); err != nil {
+		log.Printf("ERROR write metrics: %v\n", err)
+	}
 }
 
-type floatPv struct {
-	v float64
-}
+// snapshot returns the Sink and a copy of the metrics to be passed thereto.
+func (m *SimpleMetrics) snapshot(endTime time.Time) (Sink, Snapshot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-func (t floatPv) Log(key string, e *zerolog.Event) {
-	e.Float64(key, t.v)
-}
+	sink := m.sink
+	if sink == nil {
+		sink = &WriterSink{Writer: m.out, EMF: m.emf}
+	}
 
-type interPv struct {
-	v interface{}
-}
+	s := Snapshot{
+		StartTime:  m.startTime,
+		EndTime:    endTime,
+		Properties: make(map[string]interface{}, len(m.properties)),
+		Counters:   make(map[string]int64, len(m.counters)),
+		Floaters:   make(map[string]float64, len(m.floaters)),
+		Timings:    make(map[string]TimingStats, len(m.timings)),
+	}
+	for k, v := range m.properties {
+		s.Properties[k] = v
+	}
+	for k, v := range m.counters {
+		s.Counters[k] = v
+	}
+	for k, v := range m.floaters {
+		s.Floaters[k] = v
+	}
+	for k, v := range m.timings {
+		v.Histogram = v.Histogram.Clone()
+		s.Timings[k] = v
+	}
 
-func (t interPv) Log(key string, e *zerolog.Event) {
-	e.Interface(key, t.v)
+	return sink, s
 }
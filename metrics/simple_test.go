@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSimpleMetrics_Child(t *testing.T) {
+	var buf bytes.Buffer
+	parent := NewSimpleMetricsContext(context.Background(), "my-request-id", 0).(*SimpleMetrics).SetOutput(&buf)
+
+	for i := 0; i < 2; i++ {
+		child := parent.Child("record").IncrementCount("processed").AddTiming("latency", time.Millisecond)
+		child.Child("s3").AddCount("bytes", 10).Log()
+		child.Log()
+	}
+	parent.Log()
+
+	var lines []map[string]interface{}
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var v map[string]interface{}
+		if err := json.Unmarshal(line, &v); err != nil {
+			t.Fatalf("Log() is not JSON: %v: %s", err, line)
+		}
+		lines = append(lines, v)
+	}
+	if len(lines) != 5 {
+		t.Fatalf("Log() wrote %d lines, want 5", len(lines))
+	}
+
+	for i, want := range []interface{}{"record.s3", "record", "record.s3", "record", nil} {
+		if got := lines[i][PropertyKeyScope]; got != want {
+			t.Errorf("line %d %s = %v, want %v", i, PropertyKeyScope, got, want)
+		}
+		if got := lines[i]["requestId"]; got != "my-request-id" {
+			t.Errorf("line %d requestId = %v, want my-request-id", i, got)
+		}
+	}
+
+	counters := lines[4][ReservedKeyCounters].(map[string]interface{})
+	for k, want := range map[string]float64{
+		"record.processed": 2,
+		"record.s3.bytes":  20,
+		"record.fault":     0,
+	} {
+		if got := counters[k]; got != want {
+			t.Errorf("parent counters[%s] = %v, want %v", k, got, want)
+		}
+	}
+
+	timings := lines[4][ReservedKeyTimings].(map[string]interface{})
+	for k, want := range map[string]float64{
+		"record":         2,
+		"record.latency": 2,
+		"record.s3":      2,
+	} {
+		if got := timings[k].(map[string]interface{})["n"]; got != want {
+			t.Errorf("parent timings[%s].n = %v, want %v", k, got, want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package metrics
+
+import (
+	"github.com/rs/zerolog"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Sink receives the contents of a SimpleMetrics instance when it is logged.
+//
+// The default sink is a WriterSink to os.Stderr. Use SimpleMetrics.SetSink or start.WithMetricsSink to route the metrics
+// elsewhere.
+type Sink interface {
+	// Write receives the Snapshot of a SimpleMetrics instance being logged.
+	//
+	// The Snapshot is not shared with the SimpleMetrics instance so the Sink is free to retain it.
+	Write(s Snapshot) error
+}
+
+// Snapshot contains the contents of a SimpleMetrics instance at the time it is logged.
+type Snapshot struct {
+	StartTime time.Time
+	EndTime   time.Time
+	// Properties contains values of type string, int64, float64, or whatever was passed to Metrics.SetJSONProperty.
+	Properties map[string]interface{}
+	Counters   map[string]int64
+	Floaters   map[string]float64
+	Timings    map[string]TimingStats
+}
+
+// Latency returns the duration between StartTime and EndTime, which is logged as the ReservedKeyTime property.
+func (s Snapshot) Latency() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// sortedKeys returns the keys of the map in ascending order so that sinks write metrics deterministically.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// WriterSink writes metrics as structured JSON lines to an io.Writer.
+type WriterSink struct {
+	// Writer is the destination of the JSON lines. If nil, os.Stderr is used.
+	Writer io.Writer
+	// EMF changes the JSON lines to be CloudWatch Embedded Metric Format documents. See EMF.
+	EMF *EMF
+}
+
+var _ Sink = &WriterSink{}
+
+// NewWriterSink creates a WriterSink that writes to the given io.Writer.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{Writer: w}
+}
+
+func (s *WriterSink) Write(snapshot Snapshot) error {
+	w := s.Writer
+	if w == nil {
+		w = os.Stderr
+	}
+
+	logger := zerolog.New(w)
+	if s.EMF != nil {
+		writeEMF(logger, s.EMF, snapshot)
+		return nil
+	}
+
+	e := logger.Log().
+		Int64(ReservedKeyStartTime, snapshot.StartTime.UnixNano()/int64(time.Millisecond)).
+		Str(ReservedKeyEndTime, snapshot.EndTime.Format(http.TimeFormat)).
+		Str(ReservedKeyTime, FormatDuration(snapshot.Latency()))
+
+	for k, v := range snapshot.Properties {
+		logProperty(e, k, v)
+	}
+
+	if len(snapshot.Counters) != 0 {
+		c := zerolog.Dict()
+		for k, v := range snapshot.Counters {
+			c.Int64(k, v)
+		}
+		e.Dict(ReservedKeyCounters, c)
+	}
+
+	if len(snapshot.Floaters) != 0 {
+		c := zerolog.Dict()
+		for k, v := range snapshot.Floaters {
+			c.Float64(k, v)
+		}
+		e.Dict(ReservedKeyFloaters, c)
+	}
+
+	if len(snapshot.Timings) != 0 {
+		c := zerolog.Dict()
+		for k, v := range snapshot.Timings {
+			d := zerolog.Dict().
+				Str("sum", FormatDuration(v.Sum)).
+				Str("min", FormatDuration(v.Min)).
+				Str("max", FormatDuration(v.Max)).
+				Int64("n", v.N).
+				Str("avg", FormatDuration(v.Avg()))
+			if v.Histogram != nil {
+				d.Str("p50", FormatDuration(v.Histogram.Percentile(50))).
+					Str("p90", FormatDuration(v.Histogram.Percentile(90))).
+					Str("p99", FormatDuration(v.Histogram.Percentile(99)))
+			}
+			c.Dict(k, d)
+		}
+		e.Dict(ReservedKeyTimings, c)
+	}
+
+	e.Send()
+	return nil
+}
+
+func logProperty(e *zerolog.Event, key string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		e.Str(key, v)
+	case int64:
+		e.Int64(key, v)
+	case float64:
+		e.Float64(key, v)
+	default:
+		e.Interface(key, v)
+	}
+}
+
+// Recorder is a Sink that keeps every Snapshot in memory, which is useful for tests.
+//
+// The zero-value Recorder is ready for use.
+type Recorder struct {
+	snapshots []Snapshot
+	mu        sync.Mutex
+}
+
+var _ Sink = &Recorder{}
+
+func (r *Recorder) Write(s Snapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.snapshots = append(r.snapshots, s)
+	return nil
+}
+
+// Snapshots returns the snapshots that have been recorded so far in the order they were written.
+func (r *Recorder) Snapshots() []Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.snapshots)
+}
+
+// Last returns the most recently recorded Snapshot.
+func (r *Recorder) Last() (Snapshot, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.snapshots) == 0 {
+		return Snapshot{}, false
+	}
+
+	return r.snapshots[len(r.snapshots)-1], true
+}
+
+// Reset discards all recorded snapshots.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.snapshots = nil
+}
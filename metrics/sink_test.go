@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSimpleMetrics_SetSink(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Recorder{}
+	startTime := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	m := NewWithStartTime(startTime).(*SimpleMetrics).SetOutput(&buf).SetSink(r).SetTimingHistograms(true)
+	m.SetProperty("functionName", "my-function").
+		SetInt64Property("size", 3).
+		IncrementCount("requests").
+		SetFloat("bytes", 1024).
+		AddTiming("latency", 2*time.Millisecond).
+		AddTiming("latency", 3*time.Millisecond)
+	m.LogWithEndTime(startTime.Add(time.Second))
+
+	if buf.Len() != 0 {
+		t.Errorf("LogWithEndTime() wrote to output: %s", buf.String())
+	}
+
+	s, ok := r.Last()
+	if !ok {
+		t.Fatalf("Last() returns no snapshot")
+	}
+	if got := s.Latency(); got != time.Second {
+		t.Errorf("Latency() = %v, want %v", got, time.Second)
+	}
+	if got := s.Properties["functionName"]; got != "my-function" {
+		t.Errorf("Properties[functionName] = %v, want my-function", got)
+	}
+	if got := s.Properties["size"]; got != int64(3) {
+		t.Errorf("Properties[size] = %#v, want int64(3)", got)
+	}
+	if got := s.Counters["requests"]; got != 1 {
+		t.Errorf("Counters[requests] = %d, want 1", got)
+	}
+	if got := s.Counters[CounterKeyFault]; got != 0 {
+		t.Errorf("Counters[fault] = %d, want 0", got)
+	}
+	if got := s.Floaters["bytes"]; got != 1024 {
+		t.Errorf("Floaters[bytes] = %v, want 1024", got)
+	}
+	if got := s.Timings["latency"]; got.Sum != 5*time.Millisecond || got.N != 2 || got.Histogram == nil {
+		t.Errorf("Timings[latency] = %#v, want sum 5ms with 2 samples and histogram", got)
+	}
+
+	// the snapshot must not be affected by later changes to the metrics.
+	m.IncrementCount("requests").AddTiming("latency", time.Millisecond)
+	if got := s.Counters["requests"]; got != 1 {
+		t.Errorf("Counters[requests] = %d after IncrementCount, want 1", got)
+	}
+	if got := s.Timings["latency"].Histogram.N(); got != 2 {
+		t.Errorf("Timings[latency].Histogram.N() = %d after AddTiming, want 2", got)
+	}
+
+	m.Child("record").Log()
+	if got := len(r.Snapshots()); got != 2 {
+		t.Errorf("len(Snapshots()) = %d after child logged, want 2", got)
+	}
+
+	r.Reset()
+	if _, ok = r.Last(); ok {
+		t.Errorf("Last() returns snapshot after Reset()")
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	startTime := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	err := NewWriterSink(&buf).Write(Snapshot{
+		StartTime:  startTime,
+		EndTime:    startTime.Add(time.Second),
+		Properties: map[string]interface{}{"functionName": "my-function", "size": int64(3)},
+		Counters:   map[string]int64{"requests": 1},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var got map[string]interface{}
+	if err = json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Write() is not JSON: %v: %s", err, buf.String())
+	}
+
+	for k, want := range map[string]interface{}{
+		ReservedKeyStartTime: float64(startTime.UnixMilli()),
+		ReservedKeyTime:      "1000.000 ms",
+		"functionName":       "my-function",
+		"size":               3.0,
+	} {
+		if got[k] != want {
+			t.Errorf("%s = %v, want %v", k, got[k], want)
+		}
+	}
+	if got := got[ReservedKeyCounters].(map[string]interface{})["requests"]; got != 1.0 {
+		t.Errorf("counters.requests = %v, want 1", got)
+	}
+}
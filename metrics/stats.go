@@ -1,12 +1,20 @@
 package metrics
 
-import "time"
+import (
+	"iter"
+	"time"
+)
 
+// TimingStats contains the statistics of a timing metric.
 type TimingStats struct {
 	Sum time.Duration
 	Min time.Duration
 	Max time.Duration
 	N   int64
+
+	// Histogram is non-nil only if the stats were created with NewTimingStatsWithHistogram, in which case the p50, p90,
+	// and p99 percentiles are also logged.
+	Histogram *Histogram
 }
 
 func NewTimingStats(duration time.Duration) TimingStats {
@@ -18,6 +26,13 @@ func NewTimingStats(duration time.Duration) TimingStats {
 	}
 }
 
+// NewTimingStatsWithHistogram is a variant of NewTimingStats that also records the duration in a Histogram.
+func NewTimingStatsWithHistogram(duration time.Duration) TimingStats {
+	s := NewTimingStats(duration)
+	s.Histogram = NewHistogram().Add(duration)
+	return s
+}
+
 func (s *TimingStats) Add(duration time.Duration) *TimingStats {
 	s.Sum += duration
 	if s.Min > duration {
@@ -27,9 +42,54 @@ func (s *TimingStats) Add(duration time.Duration) *TimingStats {
 		s.Max = duration
 	}
 	s.N++
+	if s.Histogram != nil {
+		s.Histogram.Add(duration)
+	}
+	return s
+}
+
+// Merge adds the statistics of the other TimingStats to this one.
+//
+// The histogram is kept only if both have one; otherwise the merged percentiles would be misleading.
+func (s *TimingStats) Merge(other TimingStats) *TimingStats {
+	if s.N == 0 {
+		s.Min, s.Max = other.Min, other.Max
+	} else if other.N != 0 {
+		s.Min = min(s.Min, other.Min)
+		s.Max = max(s.Max, other.Max)
+	}
+	s.Sum += other.Sum
+
+	switch {
+	case s.N == 0:
+		s.Histogram = other.Histogram.Clone()
+	case s.Histogram != nil && other.Histogram != nil:
+		s.Histogram.Merge(other.Histogram)
+	case other.N != 0:
+		s.Histogram = nil
+	}
+	s.N += other.N
+
 	return s
 }
 
 func (s *TimingStats) Avg() time.Duration {
 	return s.Sum / time.Duration(s.N)
 }
+
+// Samples approximates the individual durations that make up the stats, each with the number of times it was recorded.
+//
+// If the stats have a Histogram, the samples are the midpoints of its non-empty buckets. Otherwise, the only sample is
+// the mean recorded N times. Either way, the counts add up to N so that sinks that only accept individual values can
+// reproduce the correct count and average.
+func (s *TimingStats) Samples() iter.Seq2[time.Duration, int64] {
+	if s.Histogram != nil {
+		return s.Histogram.Buckets()
+	}
+
+	return func(yield func(time.Duration, int64) bool) {
+		if s.N != 0 {
+			yield(s.Avg(), s.N)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultStatsDMaxPacketSize is the default StatsDSink.MaxPacketSize, chosen to fit in a single Ethernet frame.
+const DefaultStatsDMaxPacketSize = 1432
+
+// StatsDSink is a Sink that sends metrics to a StatsD server using its line protocol over UDP.
+//
+// Counters are sent as counters ("c"), floaters as gauges ("g"), and timings as timers ("ms") in milliseconds. A timing
+// with more than one duration is sent as its TimingStats.Samples: each sample is sent once with the sample rate
+// ("|@") 1/count so that the server counts it count times. The ReservedKeyTime latency of the invocation is also sent
+// as a timer. Properties are not sent unless their keys are listed in Tags, in which case they are sent as DogStatsD
+// tags.
+//
+// A StatsDSink must be created with NewStatsDSink; the exported fields can be changed afterwards.
+type StatsDSink struct {
+	// Prefix is prepended to the name of every metric, e.g. "myapp.".
+	Prefix string
+	// Tags contains the keys of the properties whose values are sent as DogStatsD tags (e.g. "|#key:value").
+	Tags []string
+	// MaxPacketSize is the maximum size of a UDP packet. Lines are packed into as few packets as possible.
+	//
+	// Defaults to DefaultStatsDMaxPacketSize.
+	MaxPacketSize int
+
+	conn net.Conn
+}
+
+var _ Sink = &StatsDSink{}
+
+// errStatsDSinkNotDialled is returned by a StatsDSink that was not created with NewStatsDSink.
+var errStatsDSinkNotDialled = errors.New("StatsDSink must be created with NewStatsDSink")
+
+// NewStatsDSink creates a StatsDSink that sends metrics to the given address (e.g. "127.0.0.1:8125").
+//
+// Caller must close the returned StatsDSink when it's no longer needed.
+func NewStatsDSink(addr string) (*StatsDSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial StatsD server error: %w", err)
+	}
+
+	return &StatsDSink{MaxPacketSize: DefaultStatsDMaxPacketSize, conn: conn}, nil
+}
+
+// Close closes the underlying UDP connection.
+func (s *StatsDSink) Close() error {
+	if s.conn == nil {
+		return errStatsDSinkNotDialled
+	}
+
+	return s.conn.Close()
+}
+
+func (s *StatsDSink) Write(snapshot Snapshot) error {
+	if s.conn == nil {
+		return errStatsDSinkNotDialled
+	}
+
+	var tags string
+	if len(s.Tags) != 0 {
+		var b strings.Builder
+		for _, k := range s.Tags {
+			v, ok := snapshot.Properties[k]
+			if !ok {
+				continue
+			}
+
+			if b.Len() == 0 {
+				b.WriteString("|#")
+			} else {
+				b.WriteByte(',')
+			}
+			b.WriteString(statsDTag(k))
+			b.WriteByte(':')
+			b.WriteString(statsDTag(fmt.Sprint(v)))
+		}
+		tags = b.String()
+	}
+
+	maxPacketSize := s.MaxPacketSize
+	if maxPacketSize <= 0 {
+		maxPacketSize = DefaultStatsDMaxPacketSize
+	}
+
+	var (
+		buf  bytes.Buffer
+		errs []error
+	)
+	add := func(name, value, metricType string) {
+		line := s.Prefix + statsDName(name) + ":" + value + "|" + metricType + tags
+		if buf.Len() != 0 && buf.Len()+1+len(line) > maxPacketSize {
+			if _, err := s.conn.Write(buf.Bytes()); err != nil {
+				errs = append(errs, err)
+			}
+			buf.Reset()
+		}
+
+		if buf.Len() != 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(line)
+	}
+
+	add(ReservedKeyTime, formatStatsDFloat(milliseconds(snapshot.Latency())), "ms")
+	for _, k := range sortedKeys(snapshot.Counters) {
+		add(k, strconv.FormatInt(snapshot.Counters[k], 10), "c")
+	}
+	for _, k := range sortedKeys(snapshot.Floaters) {
+		add(k, formatStatsDFloat(snapshot.Floaters[k]), "g")
+	}
+	for _, k := range sortedKeys(snapshot.Timings) {
+		v := snapshot.Timings[k]
+		for d, n := range v.Samples() {
+			metricType := "ms"
+			if n > 1 {
+				metricType += "|@" + formatStatsDFloat(1/float64(n))
+			}
+			add(k, formatStatsDFloat(milliseconds(d)), metricType)
+		}
+	}
+
+	if _, err := s.conn.Write(buf.Bytes()); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("send metrics to StatsD server error: %w", err)
+	}
+
+	return nil
+}
+
+// statsDName replaces the characters that are reserved by the line protocol.
+var statsDName = strings.NewReplacer(":", "_", "|", "_", "@", "_", "\n", "_").Replace
+
+// statsDTag replaces the characters that are reserved by DogStatsD tags.
+var statsDTag = strings.NewReplacer(":", "_", "|", "_", "@", "_", ",", "_", "#", "_", "\n", "_").Replace
+
+func formatStatsDFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
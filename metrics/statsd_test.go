@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsDSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	defer conn.Close()
+
+	sink, err := NewStatsDSink(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("NewStatsDSink() error = %v", err)
+	}
+	defer sink.Close()
+	sink.Prefix = "app."
+	sink.Tags = []string{"functionName", "missing"}
+
+	startTime := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	err = sink.Write(Snapshot{
+		StartTime:  startTime,
+		EndTime:    startTime.Add(1500 * time.Microsecond),
+		Properties: map[string]interface{}{"functionName": "my:function"},
+		Counters:   map[string]int64{"requests": 2, "fault": 0},
+		Floaters:   map[string]float64{"bytes": 1024.5},
+		Timings:    map[string]TimingStats{"s3|get": {Sum: 5 * time.Millisecond, N: 2}},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	buf := make([]byte, DefaultStatsDMaxPacketSize)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+
+	want := []string{
+		"app.time:1.5|ms|#functionName:my_function",
+		"app.fault:0|c|#functionName:my_function",
+		"app.requests:2|c|#functionName:my_function",
+		"app.bytes:1024.5|g|#functionName:my_function",
+		"app.s3_get:2.5|ms|@0.5|#functionName:my_function",
+	}
+	if got := string(buf[:n]); got != strings.Join(want, "\n") {
+		t.Errorf("Write() sent %q, want %q", got, strings.Join(want, "\n"))
+	}
+}
+
+func TestStatsDSink_MaxPacketSize(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	defer conn.Close()
+
+	sink, err := NewStatsDSink(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("NewStatsDSink() error = %v", err)
+	}
+	defer sink.Close()
+	sink.MaxPacketSize = 20
+
+	startTime := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	err = sink.Write(Snapshot{
+		StartTime: startTime,
+		EndTime:   startTime.Add(time.Millisecond),
+		Counters:  map[string]int64{"a": 1, "b": 2},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	buf := make([]byte, DefaultStatsDMaxPacketSize)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for _, want := range []string{"time:1|ms\na:1|c", "b:2|c"} {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("ReadFrom() error = %v", err)
+		}
+		if got := string(buf[:n]); got != want {
+			t.Errorf("Write() sent %q, want %q", got, want)
+		}
+	}
+}
+
+func TestStatsDSink_notDialled(t *testing.T) {
+	sink := &StatsDSink{Prefix: "app."}
+	if err := sink.Write(Snapshot{}); !errors.Is(err, errStatsDSinkNotDialled) {
+		t.Errorf("Write() error = %v, want %v", err, errStatsDSinkNotDialled)
+	}
+	if err := sink.Close(); !errors.Is(err, errStatsDSinkNotDialled) {
+		t.Errorf("Close() error = %v, want %v", err, errStatsDSinkNotDialled)
+	}
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"context"
+	"time"
+)
+
+// Suffixes of the counters emitted by Measure and MeasureValue.
+const (
+	CounterSuffixSuccess = ".success"
+	CounterSuffixFailure = ".failure"
+)
+
+type measureKey struct{}
+
+// Time starts a timer that adds to the timing metric of the specified key in the Metrics instance from Ctx.
+//
+// The returned function stops the timer, records and returns the elapsed duration. Only the first call records the
+// timing; subsequent calls return the same duration. If called from within Measure or MeasureValue, the key is
+// prefixed with the keys of the enclosing measurements.
+//
+// Usage:
+//
+//	defer metrics.Time(ctx, "getItem")()
+func Time(ctx context.Context, key string) func() time.Duration {
+	key = measuredKey(ctx, key)
+	startTime := time.Now()
+
+	var (
+		stopped bool
+		elapsed time.Duration
+	)
+	return func() time.Duration {
+		if !stopped {
+			stopped = true
+			elapsed = time.Since(startTime)
+			Ctx(ctx).AddTiming(key, elapsed)
+		}
+
+		return elapsed
+	}
+}
+
+// Measure invokes fn and records its latency as the timing metric of the specified key in the Metrics instance from
+// Ctx, along with the counters key+CounterSuffixSuccess and key+CounterSuffixFailure depending on whether fn returns
+// an error or panics. Panics are re-panicked after the metrics have been recorded.
+//
+// The context passed to fn nests further measurements so that they use dotted keys. For example, a Measure "getItem"
+// inside a Measure "load" records the timing "load.getItem".
+//
+// Returns the error from fn.
+func Measure(ctx context.Context, key string, fn func(ctx context.Context) error) error {
+	_, err := MeasureValue(ctx, key, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// MeasureValue is a variant of Measure for functions that also return a value.
+func MeasureValue[T any](ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (v T, err error) {
+	key = measuredKey(ctx, key)
+	m := Ctx(ctx)
+	startTime := time.Now()
+
+	ok := false
+	defer func() {
+		m.AddTiming(key, time.Since(startTime))
+		if ok && err == nil {
+			m.AddCount(key+CounterSuffixSuccess, 1, key+CounterSuffixFailure)
+		} else {
+			m.AddCount(key+CounterSuffixFailure, 1, key+CounterSuffixSuccess)
+		}
+	}()
+
+	v, err = fn(context.WithValue(ctx, measureKey{}, key+"."))
+	ok = true
+	return
+}
+
+// measuredKey prefixes the key with the keys of the enclosing measurements.
+func measuredKey(ctx context.Context, key string) string {
+	if prefix, ok := ctx.Value(measureKey{}).(string); ok {
+		return prefix + key
+	}
+
+	return key
+}
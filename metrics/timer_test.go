@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMeasure(t *testing.T) {
+	r := &Recorder{}
+	m := New().(*SimpleMetrics).SetSink(r)
+	ctx := m.WithContext(context.Background())
+
+	err := Measure(ctx, "load", func(ctx context.Context) error {
+		stop := Time(ctx, "parse")
+		time.Sleep(time.Millisecond)
+		if d := stop(); d < time.Millisecond {
+			t.Errorf("stop() = %v, want at least 1ms", d)
+		}
+		stop()
+
+		v, err := MeasureValue(ctx, "getItem", func(ctx context.Context) (string, error) {
+			return "item", nil
+		})
+		if v != "item" || err != nil {
+			t.Errorf("MeasureValue() = (%v, %v), want (item, nil)", v, err)
+		}
+
+		return errors.New("failed")
+	})
+	if err == nil || err.Error() != "failed" {
+		t.Errorf("Measure() error = %v, want failed", err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Measure() did not re-panic")
+			}
+		}()
+		_ = Measure(ctx, "panic", func(ctx context.Context) error {
+			panic("oops")
+		})
+	}()
+
+	m.Log()
+	s, _ := r.Last()
+
+	for k, want := range map[string]int64{
+		"load.success":         0,
+		"load.failure":         1,
+		"load.getItem.success": 1,
+		"load.getItem.failure": 0,
+		"panic.success":        0,
+		"panic.failure":        1,
+	} {
+		if got, ok := s.Counters[k]; !ok || got != want {
+			t.Errorf("Counters[%s] = %d (exists: %t), want %d", k, got, ok, want)
+		}
+	}
+	for _, k := range []string{"load", "load.parse", "load.getItem", "panic"} {
+		if got := s.Timings[k].N; got != 1 {
+			t.Errorf("Timings[%s].N = %d, want 1", k, got)
+		}
+	}
+	if got := s.Timings["load.parse"].Sum; got < time.Millisecond {
+		t.Errorf("Timings[load.parse].Sum = %v, want at least 1ms", got)
+	}
+}
+
+func TestTime_withoutMetrics(t *testing.T) {
+	// must not panic when the context has no Metrics instance.
+	Time(context.Background(), "key")()
+}
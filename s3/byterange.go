@@ -0,0 +1,210 @@
+package s3
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxRanges is the maximum number of ranges that ParseRange accepts before it ignores the "Range" header.
+//
+// Every range of a multipart/byteranges response is a separate GetObject request so this bounds the fan-out of a
+// single request.
+const MaxRanges = 16
+
+// ErrRangeNotSatisfiable is returned by ParseRange if none of the ranges overlap the object.
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// ByteRange is a single range of a "Range" request header resolved against the size of the object.
+//
+// Both Start and End are inclusive offsets.
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// Length returns the number of bytes in the range.
+func (r ByteRange) Length() int64 {
+	return r.End - r.Start + 1
+}
+
+// String returns the range as the value of a "Range" header, e.g. "bytes=0-499", suitable for [s3.GetObjectInput.Range].
+func (r ByteRange) String() string {
+	return "bytes=" + strconv.FormatInt(r.Start, 10) + "-" + strconv.FormatInt(r.End, 10)
+}
+
+// ContentRange returns the value of the "Content-Range" header for the range, e.g. "bytes 0-499/1234".
+func (r ByteRange) ContentRange(size int64) string {
+	return "bytes " + strconv.FormatInt(r.Start, 10) + "-" + strconv.FormatInt(r.End, 10) + "/" + strconv.FormatInt(size, 10)
+}
+
+// ParseRange parses the "Range" request header against an object of the given size.
+//
+// Only the "bytes" unit is supported. Returns nil, nil if the header is empty, uses another unit, is malformed, or has
+// more than MaxRanges ranges, in which case the header should be ignored and the full object returned (see
+// https://www.rfc-editor.org/rfc/rfc9110#section-14.2). Ranges that start past the end of the object are dropped, and
+// if no range remains, returns ErrRangeNotSatisfiable.
+func ParseRange(value string, size int64) ([]ByteRange, error) {
+	specs, ok := strings.CutPrefix(value, "bytes=")
+	if !ok {
+		return nil, nil
+	}
+
+	values := strings.Split(specs, ",")
+	if len(values) > MaxRanges {
+		return nil, nil
+	}
+
+	ranges := make([]ByteRange, 0, len(values))
+	for _, v := range values {
+		first, last, ok := strings.Cut(strings.TrimSpace(v), "-")
+		if !ok {
+			return nil, nil
+		}
+
+		var r ByteRange
+		switch {
+		case first == "":
+			// suffix range "-500" is the last 500 bytes.
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, nil
+			}
+			if n == 0 || size == 0 {
+				continue
+			}
+			r = ByteRange{Start: max(size-n, 0), End: size - 1}
+		default:
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, nil
+			}
+			end := size - 1
+			if last != "" {
+				if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+					return nil, nil
+				}
+			}
+			if start >= size {
+				continue
+			}
+			r = ByteRange{Start: start, End: min(end, size-1)}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		return nil, ErrRangeNotSatisfiable
+	}
+
+	return ranges, nil
+}
+
+// GetObjectAPIClient abstracts s3.Client.GetObject.
+type GetObjectAPIClient interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// GetObjectRanges returns a multipart/byteranges body containing the given ranges of an object of the given size.
+//
+// S3 only supports one range per GetObject, so each range is downloaded with its own GetObject request using a copy of
+// the input whose Range is replaced. The requests are made lazily and sequentially as the body is read, so the body
+// can be streamed without buffering the parts. Set [s3.GetObjectInput.IfMatch] to the ETag of the object to guarantee
+// that all parts come from the same version of the object; a mismatch fails the read of the body.
+//
+// The partContentType is the "Content-Type" of every part, usually the "Content-Type" of the object. Returns the body,
+// which must be closed, the "Content-Type" of the response (including the boundary), and the exact number of bytes
+// of the body.
+func GetObjectRanges(ctx context.Context, client GetObjectAPIClient, input *s3.GetObjectInput, ranges []ByteRange, size int64, partContentType string, optFns ...func(*s3.Options)) (body io.ReadCloser, contentType string, contentLength int64) {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	boundary := hex.EncodeToString(b)
+
+	r := &rangesReader{}
+	readers := make([]io.Reader, 0, 2*len(ranges)+1)
+	for i, br := range ranges {
+		header := "--" + boundary + "\r\nContent-Type: " + partContentType + "\r\nContent-Range: " + br.ContentRange(size) + "\r\n\r\n"
+		if i > 0 {
+			header = "\r\n" + header
+		}
+
+		in := *input
+		in.Range = aws.String(br.String())
+		part := &lazyObject{open: func() (io.ReadCloser, error) {
+			output, err := client.GetObject(ctx, &in, optFns...)
+			if err != nil {
+				return nil, err
+			}
+			return output.Body, nil
+		}}
+
+		readers = append(readers, strings.NewReader(header), part)
+		r.parts = append(r.parts, part)
+		contentLength += int64(len(header)) + br.Length()
+	}
+
+	trailer := "\r\n--" + boundary + "--\r\n"
+	readers = append(readers, strings.NewReader(trailer))
+	contentLength += int64(len(trailer))
+
+	r.Reader = io.MultiReader(readers...)
+	return r, "multipart/byteranges; boundary=" + boundary, contentLength
+}
+
+// rangesReader closes the parts that have been opened but not fully read.
+type rangesReader struct {
+	io.Reader
+	parts []*lazyObject
+}
+
+func (r *rangesReader) Close() (err error) {
+	for _, p := range r.parts {
+		err = errors.Join(err, p.Close())
+	}
+	return
+}
+
+// lazyObject opens the object on first read and closes it upon EOF.
+type lazyObject struct {
+	open func() (io.ReadCloser, error)
+	rc   io.ReadCloser
+	done bool
+}
+
+func (o *lazyObject) Read(p []byte) (n int, err error) {
+	if o.done {
+		return 0, io.EOF
+	}
+
+	if o.rc == nil {
+		if o.rc, err = o.open(); err != nil {
+			o.done = true
+			return 0, err
+		}
+	}
+
+	if n, err = o.rc.Read(p); err == io.EOF {
+		err = o.Close()
+		if err == nil {
+			err = io.EOF
+		}
+	}
+
+	return
+}
+
+func (o *lazyObject) Close() (err error) {
+	o.done = true
+	if o.rc != nil {
+		err = o.rc.Close()
+		o.rc = nil
+	}
+	return
+}
@@ -0,0 +1,112 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"io"
+	"mime"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []ByteRange
+		wantErr error
+	}{
+		{name: "empty", value: ""},
+		{name: "other unit", value: "items=0-1"},
+		{name: "malformed", value: "bytes=abc"},
+		{name: "reversed", value: "bytes=5-1"},
+		{name: "single", value: "bytes=0-99", want: []ByteRange{{0, 99}}},
+		{name: "open-ended", value: "bytes=900-", want: []ByteRange{{900, 999}}},
+		{name: "suffix", value: "bytes=-100", want: []ByteRange{{900, 999}}},
+		{name: "suffix larger than size", value: "bytes=-2000", want: []ByteRange{{0, 999}}},
+		{name: "end past size", value: "bytes=500-5000", want: []ByteRange{{500, 999}}},
+		{name: "multiple", value: "bytes=0-99, 200-299,-1", want: []ByteRange{{0, 99}, {200, 299}, {999, 999}}},
+		{name: "drops unsatisfiable", value: "bytes=0-99,1000-1099", want: []ByteRange{{0, 99}}},
+		{name: "not satisfiable", value: "bytes=1000-", wantErr: ErrRangeNotSatisfiable},
+		{name: "too many", value: "bytes=" + strings.Repeat("0-1,", MaxRanges) + "0-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRange(tt.value, 1000)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRange() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeGetObjectClient struct {
+	content string
+	ifMatch []string
+}
+
+func (f *fakeGetObjectClient) GetObject(_ context.Context, input *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	f.ifMatch = append(f.ifMatch, aws.ToString(input.IfMatch))
+
+	first, last, _ := strings.Cut(strings.TrimPrefix(aws.ToString(input.Range), "bytes="), "-")
+	start, _ := strconv.Atoi(first)
+	end, _ := strconv.Atoi(last)
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(f.content[start : end+1]))}, nil
+}
+
+func TestGetObjectRanges(t *testing.T) {
+	client := &fakeGetObjectClient{content: "0123456789abcdefghij"}
+	ranges := []ByteRange{{0, 3}, {10, 12}}
+
+	body, contentType, contentLength := GetObjectRanges(context.Background(), client, &s3.GetObjectInput{IfMatch: aws.String(`"abc"`)}, ranges, 20, "text/plain")
+	defer body.Close()
+
+	if len(client.ifMatch) != 0 {
+		t.Errorf("GetObject was called %d times before reading", len(client.ifMatch))
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if int64(len(data)) != contentLength {
+		t.Errorf("contentLength = %d, want %d", contentLength, len(data))
+	}
+	if !reflect.DeepEqual(client.ifMatch, []string{`"abc"`, `"abc"`}) {
+		t.Errorf("IfMatch = %v", client.ifMatch)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/byteranges" {
+		t.Fatalf("contentType = %q", contentType)
+	}
+
+	r := multipart.NewReader(strings.NewReader(string(data)), params["boundary"])
+	var got []string
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+
+		b, _ := io.ReadAll(p)
+		got = append(got, p.Header.Get("Content-Range")+" "+p.Header.Get("Content-Type")+" "+string(b))
+	}
+
+	want := []string{"bytes 0-3/20 text/plain 0123", "bytes 10-12/20 text/plain abc"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parts = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,40 @@
+package s3
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"time"
+)
+
+// PresignGetObjectAPIClient abstracts s3.PresignClient.PresignGetObject.
+type PresignGetObjectAPIClient interface {
+	PresignGetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+}
+
+// PresignGet returns a presigned GetObject URL for the object that expires after the given duration.
+//
+// If ExpectedBucketOwner is not empty, it is signed into the query string of the URL so that S3 rejects the request
+// if the bucket is owned by a different account. The input can be used to customise other fields such as
+// ResponseContentDisposition; its Bucket, Key, and ExpectedBucketOwner are overwritten. If given a nil input, a new
+// one will be created.
+func (u URIWithOwner) PresignGet(ctx context.Context, client PresignGetObjectAPIClient, input *s3.GetObjectInput, expires time.Duration) (string, error) {
+	if input == nil {
+		input = &s3.GetObjectInput{}
+	}
+
+	input.Bucket = aws.String(u.Bucket)
+	input.Key = aws.String(u.Key)
+	input.ExpectedBucketOwner = nil
+	if u.ExpectedBucketOwner != "" {
+		input.ExpectedBucketOwner = aws.String(u.ExpectedBucketOwner)
+	}
+
+	req, err := client.PresignGetObject(ctx, input, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+
+	return req.URL, nil
+}
@@ -7,8 +7,9 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/nguyengg/golambda/configsupport"
 	"github.com/nguyengg/golambda/logsupport"
-	"github.com/nguyengg/golambda/metrics"
 	"github.com/nguyengg/golambda/start"
+	"github.com/nguyengg/golambda/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"log"
 )
 
@@ -17,13 +18,21 @@ type Handler func(ctx context.Context, request events.S3Event) error
 
 // Start starts the Lambda runtime loop with the specified Handler.
 func Start(handler Handler, options ...start.Option) {
+	lambda.Start(Wrap(handler, options...))
+}
+
+// Wrap returns the handler that Start passes to the Lambda runtime, which is the given handler wrapped with metrics,
+// logging, and middlewares according to the options.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. in tests.
+func Wrap(handler Handler, options ...start.Option) Handler {
 	opts := start.New(options)
+	h := start.Chain(opts, func(ctx context.Context, request events.S3Event) (struct{}, error) {
+		return struct{}{}, handler(ctx, request)
+	})
 
-	lambda.Start(func(ctx context.Context, request events.S3Event) (err error) {
-		m := metrics.NewSimpleMetricsContext(
-			opts.LoggerProvider(ctx).WithContext(ctx),
-			"",
-			0)
+	return func(ctx context.Context, request events.S3Event) (err error) {
+		m := opts.NewMetrics(ctx, "", 0)
 		ctx = m.WithContext(ctx)
 
 		if !opts.DisableSetUpGlobalLogger {
@@ -41,6 +50,12 @@ func Start(handler Handler, options ...start.Option) {
 
 		panicked := true
 
+		ctx, span := opts.StartSpan(ctx, "s3event",
+			attribute.Int("recordCount", len(request.Records)))
+		defer func() {
+			tracing.End(span, err, panicked)
+		}()
+
 		if !opts.DisableMetricsLogging {
 			m.AddCount("recordCount", int64(len(request.Records)))
 
@@ -56,8 +71,16 @@ func Start(handler Handler, options ...start.Option) {
 			}()
 		}
 
-		err = handler(ctx, request)
+		if opts.RecoverPanics {
+			defer func() {
+				if r := recover(); r != nil {
+					err = opts.Recover(ctx, r)
+				}
+			}()
+		}
+
+		_, err = h(ctx, request)
 		panicked = false
 		return
-	})
+	}
 }
@@ -0,0 +1,58 @@
+package snsevent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/nguyengg/golambda/metrics"
+	"github.com/nguyengg/golambda/start"
+)
+
+// CounterKeyDecodeFailure is the counter that is incremented for every record whose message cannot be decoded.
+const CounterKeyDecodeFailure = "decodeFailureCount"
+
+// TypedHandler is the handler for individual SNS records whose message is decoded as JSON into T.
+type TypedHandler[T any] func(ctx context.Context, record events.SNSEventRecord, message T) error
+
+// StartTyped is a variant of Start that decodes the message of every record into T.
+//
+// See NewTypedHandler for how records are processed.
+func StartTyped[T any](handler TypedHandler[T], options ...start.Option) {
+	lambda.Start(WrapTyped(handler, options...))
+}
+
+// WrapTyped returns the handler that StartTyped passes to the Lambda runtime.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. in tests.
+func WrapTyped[T any](handler TypedHandler[T], options ...start.Option) Handler {
+	return Wrap(NewTypedHandler(handler), options...)
+}
+
+// NewTypedHandler converts a TypedHandler into a Handler that decodes the message of every record into T.
+//
+// Every record is processed even if an earlier one fails. If a message cannot be decoded, the handler is not invoked
+// for that record and the CounterKeyDecodeFailure counter is incremented. The returned Handler returns all record
+// failures joined together with errors.Join.
+func NewTypedHandler[T any](handler TypedHandler[T]) Handler {
+	return func(ctx context.Context, request events.SNSEvent) error {
+		var errs []error
+
+		for _, record := range request.Records {
+			var message T
+			if err := json.Unmarshal([]byte(record.SNS.Message), &message); err != nil {
+				metrics.Ctx(ctx).IncrementCount(CounterKeyDecodeFailure)
+				errs = append(errs, fmt.Errorf("decode message %s: %w", record.SNS.MessageID, err))
+				continue
+			}
+
+			if err := handler(ctx, record, message); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		return errors.Join(errs...)
+	}
+}
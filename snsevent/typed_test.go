@@ -0,0 +1,44 @@
+package snsevent
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/golambdatest"
+	"reflect"
+	"testing"
+)
+
+func TestWrapTyped(t *testing.T) {
+	type Message struct {
+		Name string `json:"name"`
+	}
+
+	errHandler := errors.New("handler error")
+
+	var got []string
+	r := golambdatest.NewRecorder()
+	h := WrapTyped(func(ctx context.Context, record events.SNSEventRecord, message Message) error {
+		got = append(got, message.Name)
+		if message.Name == "fail" {
+			return errHandler
+		}
+		return nil
+	}, r.Options()...)
+
+	err := h(golambdatest.NewContext(context.Background()), events.SNSEvent{Records: []events.SNSEventRecord{
+		{SNS: events.SNSEntity{MessageID: "1", Message: `{"name":"hello"}`}},
+		{SNS: events.SNSEntity{MessageID: "2", Message: `not json`}},
+		{SNS: events.SNSEntity{MessageID: "3", Message: `{"name":"fail"}`}},
+		{SNS: events.SNSEntity{MessageID: "4", Message: `{"name":"world"}`}},
+	}})
+	if !errors.Is(err, errHandler) {
+		t.Errorf("WrapTyped() error = %v, want %v", err, errHandler)
+	}
+
+	if want := []string{"hello", "fail", "world"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WrapTyped() got = %v, want %v", got, want)
+	}
+
+	r.LastMetrics(t).AssertCounter(t, CounterKeyDecodeFailure, 1)
+}
@@ -0,0 +1,214 @@
+package sqsevent
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/metrics"
+	"github.com/nguyengg/golambda/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"sync"
+	"time"
+)
+
+// Per-record metrics keys. See MessageHandlerOpts.DisableRecordMetrics.
+const (
+	CounterKeyRecordSuccess = "recordSuccessCount"
+	CounterKeyRecordFailure = "recordFailureCount"
+	CounterKeyRecordSkipped = "recordSkippedCount"
+	TimingKeyRecordLatency  = "recordLatency"
+)
+
+// ChildMetricsName is the name of the child metrics created for every record. See MessageHandlerOpts.ChildMetrics.
+const ChildMetricsName = "record"
+
+// MessageHandlerOpts contains customisable settings for how NewMessageHandler processes individual records.
+type MessageHandlerOpts struct {
+	// Concurrency is the maximum number of records (or message groups if FIFO is true) that are processed in parallel.
+	//
+	// Defaults to 1 which processes records serially in the order they appear in the batch.
+	Concurrency int
+
+	// FIFO enables strict ordering by MessageGroupId.
+	//
+	// Records of the same message group are always processed serially in the order they appear in the batch. Once a
+	// record fails, the subsequent records of the same group are not processed and are reported as failures so that
+	// they are redelivered in order. Records from different message groups may be processed in parallel according to
+	// Concurrency.
+	FIFO bool
+
+	// DisableRecordMetrics disables the per-record metrics.
+	//
+	// By default, the latency of every record is added to the TimingKeyRecordLatency timing, and the
+	// CounterKeyRecordSuccess, CounterKeyRecordFailure, and CounterKeyRecordSkipped counters are updated accordingly.
+	DisableRecordMetrics bool
+
+	// ChildMetrics gives every record its own metrics.Metrics created with metrics.Metrics.Child.
+	//
+	// The MessageHandler can use metrics.Ctx to add metrics for the record being processed. The child metrics has a
+	// "messageId" property, is logged as its own line once the record has been processed, and is rolled up into the
+	// invocation's metrics with ChildMetricsName as the key prefix.
+	ChildMetrics bool
+}
+
+// NewMessageHandler converts a MessageHandler into a Handler that reports a batch item failure for every record that
+// fails processing.
+//
+// Usage:
+//
+//	sqsevent.Start(sqsevent.NewMessageHandler(handler, func(opts *sqsevent.MessageHandlerOpts) {
+//		opts.Concurrency = 10
+//		opts.FIFO = true
+//	}))
+//
+// If the MessageHandler panics, the panic is propagated to the caller on the same goroutine that invokes the Handler so
+// that start.Options.RecoverPanics still works even if records are processed in parallel.
+func NewMessageHandler(handler MessageHandler, opts ...func(*MessageHandlerOpts)) Handler {
+	o := &MessageHandlerOpts{Concurrency: 1}
+	for _, fn := range opts {
+		fn(o)
+	}
+	if o.Concurrency < 1 {
+		o.Concurrency = 1
+	}
+
+	return func(ctx context.Context, request events.SQSEvent) (response events.SQSEventResponse, err error) {
+		failed := make([]bool, len(request.Records))
+
+		// each group is processed serially, and the records of a group are written only by that group's goroutine.
+		process := func(group []int) {
+			for i, index := range group {
+				if o.process(ctx, handler, request.Records[index]) {
+					continue
+				}
+
+				failed[index] = true
+				if o.FIFO {
+					for _, rest := range group[i+1:] {
+						failed[rest] = true
+					}
+					if !o.DisableRecordMetrics {
+						metrics.Ctx(ctx).AddCount(CounterKeyRecordSkipped, int64(len(group)-i-1))
+					}
+					return
+				}
+			}
+		}
+
+		groups := o.group(request.Records)
+		if o.Concurrency == 1 {
+			for _, group := range groups {
+				process(group)
+			}
+		} else {
+			var (
+				wg       sync.WaitGroup
+				mu       sync.Mutex
+				sem      = make(chan struct{}, o.Concurrency)
+				panicked bool
+				v        interface{}
+			)
+
+			for _, group := range groups {
+				sem <- struct{}{}
+				wg.Add(1)
+
+				go func(group []int) {
+					defer func() {
+						if r := recover(); r != nil {
+							mu.Lock()
+							if !panicked {
+								panicked, v = true, r
+							}
+							mu.Unlock()
+						}
+
+						<-sem
+						wg.Done()
+					}()
+
+					process(group)
+				}(group)
+			}
+
+			wg.Wait()
+
+			if panicked {
+				panic(v)
+			}
+		}
+
+		for i, record := range request.Records {
+			if failed[i] {
+				response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: record.MessageId})
+			}
+		}
+
+		return
+	}
+}
+
+// group returns the indices of the records grouped by the order in which they must be processed.
+//
+// If FIFO is false, every record is its own group. Otherwise, records are grouped by MessageGroupId while preserving
+// their order within the batch; records without a MessageGroupId are their own groups.
+func (o *MessageHandlerOpts) group(records []events.SQSMessage) [][]int {
+	groups := make([][]int, 0, len(records))
+	if !o.FIFO {
+		for i := range records {
+			groups = append(groups, []int{i})
+		}
+		return groups
+	}
+
+	positions := make(map[string]int)
+	for i, record := range records {
+		id, ok := record.Attributes["MessageGroupId"]
+		if !ok || id == "" {
+			groups = append(groups, []int{i})
+			continue
+		}
+
+		if p, ok := positions[id]; ok {
+			groups[p] = append(groups[p], i)
+			continue
+		}
+
+		positions[id] = len(groups)
+		groups = append(groups, []int{i})
+	}
+
+	return groups
+}
+
+// process invokes the handler for a single record and returns true if the record was processed successfully.
+func (o *MessageHandlerOpts) process(ctx context.Context, handler MessageHandler, record events.SQSMessage) bool {
+	var err error
+	panicked := true
+
+	ctx, span := tracing.StartRecord(ctx, ChildMetricsName, attribute.String("messageId", record.MessageId))
+	defer func() {
+		tracing.End(span, err, panicked)
+	}()
+
+	parent := metrics.Ctx(ctx)
+	if o.ChildMetrics {
+		m := parent.Child(ChildMetricsName).SetProperty("messageId", record.MessageId)
+		ctx = m.WithContext(ctx)
+		defer m.Log()
+	}
+
+	startTime := time.Now()
+	err = handler(ctx, record)
+	panicked = false
+
+	if !o.DisableRecordMetrics {
+		m := parent.AddTiming(TimingKeyRecordLatency, time.Since(startTime))
+		if err != nil {
+			m.AddCount(CounterKeyRecordFailure, 1, CounterKeyRecordSuccess)
+		} else {
+			m.AddCount(CounterKeyRecordSuccess, 1, CounterKeyRecordFailure)
+		}
+	}
+
+	return err == nil
+}
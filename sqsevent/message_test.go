@@ -0,0 +1,191 @@
+package sqsevent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/golambdatest"
+	"github.com/nguyengg/golambda/metrics"
+	"github.com/nguyengg/golambda/start"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewMessageHandler_FIFO(t *testing.T) {
+	records := []events.SQSMessage{
+		newMessage("a1", "a"),
+		newMessage("b1", "b"),
+		newMessage("a2", "a"),
+		newMessage("b2", "b"),
+		newMessage("a3", "a"),
+		newMessage("c1", ""),
+	}
+
+	var mu sync.Mutex
+	var processed []string
+	r := golambdatest.NewRecorder()
+	h := Wrap(NewMessageHandler(func(ctx context.Context, message events.SQSMessage) error {
+		mu.Lock()
+		processed = append(processed, message.MessageId)
+		mu.Unlock()
+
+		if message.MessageId == "a2" {
+			return errors.New("fail")
+		}
+		return nil
+	}, func(opts *MessageHandlerOpts) {
+		opts.FIFO = true
+	}), r.Options()...)
+
+	response, err := h(golambdatest.NewContext(context.Background()), events.SQSEvent{Records: records})
+	if err != nil {
+		t.Fatalf("NewMessageHandler() error = %v", err)
+	}
+
+	if want := []string{"a1", "a2", "b1", "b2", "c1"}; !reflect.DeepEqual(processed, want) {
+		t.Errorf("NewMessageHandler() processed = %v, want %v", processed, want)
+	}
+	if want := []events.SQSBatchItemFailure{{ItemIdentifier: "a2"}, {ItemIdentifier: "a3"}}; !reflect.DeepEqual(response.BatchItemFailures, want) {
+		t.Errorf("NewMessageHandler() failures = %v, want %v", response.BatchItemFailures, want)
+	}
+
+	m := r.LastMetrics(t)
+	m.AssertCounter(t, CounterKeyRecordSuccess, 4)
+	m.AssertCounter(t, CounterKeyRecordFailure, 1)
+	m.AssertCounter(t, CounterKeyRecordSkipped, 1)
+	m.AssertCounter(t, "failureCount", 2)
+	if timing, ok := m.Timing(TimingKeyRecordLatency); !ok || fmt.Sprint(timing["n"]) != "5" {
+		t.Errorf("NewMessageHandler() %s = %v, want n = 5", TimingKeyRecordLatency, timing)
+	}
+}
+
+func TestNewMessageHandler_Concurrency(t *testing.T) {
+	records := make([]events.SQSMessage, 8)
+	for i := range records {
+		records[i] = newMessage(string(rune('a'+i)), "")
+	}
+
+	var running, maxRunning int32
+	h := NewMessageHandler(func(ctx context.Context, message events.SQSMessage) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		if message.MessageId == "c" {
+			return errors.New("fail")
+		}
+		return nil
+	}, func(opts *MessageHandlerOpts) {
+		opts.Concurrency = 3
+	})
+
+	response, err := h(context.Background(), events.SQSEvent{Records: records})
+	if err != nil {
+		t.Fatalf("NewMessageHandler() error = %v", err)
+	}
+	if want := []events.SQSBatchItemFailure{{ItemIdentifier: "c"}}; !reflect.DeepEqual(response.BatchItemFailures, want) {
+		t.Errorf("NewMessageHandler() failures = %v, want %v", response.BatchItemFailures, want)
+	}
+	if maxRunning < 2 || maxRunning > 3 {
+		t.Errorf("NewMessageHandler() max concurrency = %d, want between 2 and 3", maxRunning)
+	}
+}
+
+func TestNewMessageHandler_Panic(t *testing.T) {
+	h := NewMessageHandler(func(ctx context.Context, message events.SQSMessage) error {
+		panic("boom")
+	}, func(opts *MessageHandlerOpts) {
+		opts.Concurrency = 2
+	})
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("NewMessageHandler() recover() = %v, want boom", r)
+		}
+	}()
+
+	_, _ = h(context.Background(), events.SQSEvent{Records: []events.SQSMessage{newMessage("a", ""), newMessage("b", "")}})
+}
+
+func TestNewMessageHandler_ChildMetrics(t *testing.T) {
+	r := golambdatest.NewRecorder()
+	h := Wrap(NewMessageHandler(func(ctx context.Context, message events.SQSMessage) error {
+		metrics.Ctx(ctx).IncrementCount("processed")
+		return nil
+	}, func(opts *MessageHandlerOpts) {
+		opts.ChildMetrics = true
+	}), r.Options()...)
+
+	if _, err := h(golambdatest.NewContext(context.Background()), events.SQSEvent{Records: []events.SQSMessage{newMessage("a", ""), newMessage("b", "")}}); err != nil {
+		t.Fatalf("NewMessageHandler() error = %v", err)
+	}
+
+	ms := r.Metrics(t)
+	if len(ms) != 3 {
+		t.Fatalf("NewMessageHandler() logged %d metrics, want 3", len(ms))
+	}
+	for i, id := range []string{"a", "b"} {
+		ms[i].AssertProperty(t, "messageId", id)
+		ms[i].AssertProperty(t, metrics.PropertyKeyScope, ChildMetricsName)
+		ms[i].AssertCounter(t, "processed", 1)
+	}
+
+	ms[2].AssertCounter(t, ChildMetricsName+".processed", 2)
+	ms[2].AssertCounter(t, CounterKeyRecordSuccess, 2)
+}
+
+func newMessage(id, groupId string) events.SQSMessage {
+	m := events.SQSMessage{MessageId: id}
+	if groupId != "" {
+		m.Attributes = map[string]string{"MessageGroupId": groupId}
+	}
+	return m
+}
+
+func TestNewMessageHandler_tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	r := golambdatest.NewRecorder()
+	h := Wrap(NewMessageHandler(func(ctx context.Context, message events.SQSMessage) error {
+		if message.MessageId == "2" {
+			return errors.New("fail")
+		}
+		return nil
+	}), append(r.Options(), start.WithTracing(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))))...)
+
+	_, err := h(golambdatest.NewContext(context.Background()), events.SQSEvent{Records: []events.SQSMessage{newMessage("1", ""), newMessage("2", "")}})
+	if err != nil {
+		t.Fatalf("NewMessageHandler() error = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+
+	invocation := spans[2]
+	for i, want := range []codes.Code{codes.Unset, codes.Error} {
+		if got := spans[i].Parent.SpanID(); got != invocation.SpanContext.SpanID() {
+			t.Errorf("record %d parent = %v, want %v", i, got, invocation.SpanContext.SpanID())
+		}
+		if got := spans[i].Status.Code; got != want {
+			t.Errorf("record %d status = %v, want %v", i, got, want)
+		}
+	}
+	if got := invocation.Status.Code; got != codes.Unset {
+		t.Errorf("invocation status = %v, want unset", got)
+	}
+}
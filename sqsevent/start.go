@@ -7,8 +7,9 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/nguyengg/golambda/configsupport"
 	"github.com/nguyengg/golambda/logsupport"
-	"github.com/nguyengg/golambda/metrics"
 	"github.com/nguyengg/golambda/start"
+	"github.com/nguyengg/golambda/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"log"
 )
 
@@ -20,13 +21,19 @@ type MessageHandler func(context.Context, events.SQSMessage) error
 
 // Start starts the Lambda runtime loop with the specified Handler.
 func Start(handler Handler, options ...start.Option) {
+	lambda.Start(Wrap(handler, options...))
+}
+
+// Wrap returns the handler that Start passes to the Lambda runtime, which is the given handler wrapped with metrics,
+// logging, and middlewares according to the options.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. in tests.
+func Wrap(handler Handler, options ...start.Option) Handler {
 	opts := start.New(options)
+	h := start.Chain(opts, start.Handler[events.SQSEvent, events.SQSEventResponse](handler))
 
-	lambda.Start(func(ctx context.Context, request events.SQSEvent) (response events.SQSEventResponse, err error) {
-		m := metrics.NewSimpleMetricsContext(
-			opts.LoggerProvider(ctx).WithContext(ctx),
-			"",
-			0)
+	return func(ctx context.Context, request events.SQSEvent) (response events.SQSEventResponse, err error) {
+		m := opts.NewMetrics(ctx, "", 0)
 		ctx = m.WithContext(ctx)
 
 		if !opts.DisableSetUpGlobalLogger {
@@ -55,9 +62,14 @@ func Start(handler Handler, options ...start.Option) {
 
 		panicked := true
 
+		ctx, span := opts.StartSpan(ctx, "sqsevent",
+			attribute.Int("recordCount", len(request.Records)))
+		defer func() {
+			tracing.End(span, err, panicked)
+		}()
+
 		if !opts.DisableMetricsLogging {
 			m.AddCount("recordCount", int64(len(request.Records)))
-			m.AddCount("failureCount", int64(len(response.BatchItemFailures)))
 
 			defer func() {
 				if panicked {
@@ -67,81 +79,50 @@ func Start(handler Handler, options ...start.Option) {
 					m.Faulted()
 				}
 
-				m.Log()
+				m.SetCount("failureCount", int64(len(response.BatchItemFailures))).Log()
+			}()
+		}
+
+		if opts.RecoverPanics {
+			defer func() {
+				if r := recover(); r != nil {
+					_ = opts.Recover(ctx, r)
+					response, err = failAll(request), nil
+				}
 			}()
 		}
 
-		response, err = handler(m.WithContext(ctx), request)
+		response, err = h(m.WithContext(ctx), request)
 		panicked = false
 		return
-	})
+	}
 }
 
 // StartMessageHandler handles the generation of events.SQSEventResponse for caller.
 //
 // When MessageHandler returns a non-nil error for a specific message, an events.SQSBatchItemFailure will be created for
 // it. The main handler will always return a non-nil error unless panic happens.
+//
+// Records are processed serially. To process records concurrently or to respect FIFO message group ordering, use
+// NewMessageHandler with Start instead.
 func StartMessageHandler(handler MessageHandler, options ...start.Option) {
-	opts := start.New(options)
-
-	lambda.Start(func(ctx context.Context, request events.SQSEvent) (response events.SQSEventResponse, err error) {
-		m := metrics.NewSimpleMetricsContext(
-			opts.LoggerProvider(ctx).WithContext(ctx),
-			"",
-			0)
-		ctx = m.WithContext(ctx)
-
-		if !opts.DisableSetUpGlobalLogger {
-			defer logsupport.SetUpGlobalLogger(ctx)()
-		}
-
-		if !opts.DisableRequestDebugLogging && configsupport.IsDebug() {
-			data, err := json.Marshal(request)
-			if err != nil {
-				log.Printf("ERROR marshal request: %v\n", err)
-			} else {
-				log.Printf("INFO request: %s\n", data)
-			}
-		}
-
-		if !opts.DisableResponseDebugLogging && configsupport.IsDebug() {
-			defer func() {
-				data, err := json.Marshal(response)
-				if err != nil {
-					log.Printf("ERROR marshal response: %v\n", err)
-				} else {
-					log.Printf("INFO response: %s\n", data)
-				}
-			}()
-		}
-
-		panicked := true
-
-		if !opts.DisableMetricsLogging {
-			m.AddCount("recordCount", int64(len(request.Records)))
-			m.AddCount("failureCount", int64(len(response.BatchItemFailures)))
-
-			defer func() {
-				if panicked {
-					m.Panicked()
-				}
-				if err != nil {
-					m.Faulted()
-				}
-
-				m.Log()
-			}()
-		}
+	lambda.Start(WrapMessageHandler(handler, options...))
+}
 
-		ctx = m.WithContext(ctx)
+// WrapMessageHandler returns the handler that StartMessageHandler passes to the Lambda runtime, which is the given
+// handler wrapped with metrics, logging, and middlewares according to the options.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. in tests.
+func WrapMessageHandler(handler MessageHandler, options ...start.Option) Handler {
+	return Wrap(NewMessageHandler(handler), options...)
+}
 
-		for _, record := range request.Records {
-			if err := handler(ctx, record); err != nil {
-				response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: record.MessageId})
-			}
-		}
+// failAll creates an events.SQSEventResponse that reports every record as a batch item failure.
+func failAll(request events.SQSEvent) events.SQSEventResponse {
+	failures := make([]events.SQSBatchItemFailure, len(request.Records))
+	for i, record := range request.Records {
+		failures[i] = events.SQSBatchItemFailure{ItemIdentifier: record.MessageId}
+	}
 
-		panicked = false
-		return
-	})
+	return events.SQSEventResponse{BatchItemFailures: failures}
 }
@@ -0,0 +1,66 @@
+package sqsevent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/nguyengg/golambda/metrics"
+	"github.com/nguyengg/golambda/start"
+)
+
+// CounterKeyDecodeFailure is the counter that is incremented for every record whose body cannot be decoded.
+const CounterKeyDecodeFailure = "decodeFailureCount"
+
+// TypedMessageHandler is a variant of MessageHandler that receives the message body decoded as JSON into T.
+type TypedMessageHandler[T any] func(ctx context.Context, message events.SQSMessage, body T) error
+
+// StartTypedMessageHandler is a variant of StartMessageHandler that decodes the body of every message into T.
+//
+// See NewTypedMessageHandler for how the body is decoded.
+func StartTypedMessageHandler[T any](handler TypedMessageHandler[T], options ...start.Option) {
+	lambda.Start(WrapTypedMessageHandler(handler, options...))
+}
+
+// WrapTypedMessageHandler returns the handler that StartTypedMessageHandler passes to the Lambda runtime.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. in tests.
+func WrapTypedMessageHandler[T any](handler TypedMessageHandler[T], options ...start.Option) Handler {
+	return WrapMessageHandler(NewTypedMessageHandler(handler), options...)
+}
+
+// NewTypedMessageHandler converts a TypedMessageHandler into a MessageHandler that decodes the body of every message
+// into T.
+//
+// If the body is an SNS notification (i.e. the SQS queue is subscribed to an SNS topic without raw message delivery),
+// the notification's Message is decoded instead. If the body cannot be decoded, the handler is not invoked, the
+// CounterKeyDecodeFailure counter is incremented, and the record is reported as a failure.
+//
+// The returned MessageHandler can be passed to NewMessageHandler to customise concurrency and FIFO ordering.
+func NewTypedMessageHandler[T any](handler TypedMessageHandler[T]) MessageHandler {
+	return func(ctx context.Context, message events.SQSMessage) error {
+		var body T
+		if err := json.Unmarshal([]byte(unwrapSNSEnvelope(message.Body)), &body); err != nil {
+			metrics.Ctx(ctx).IncrementCount(CounterKeyDecodeFailure)
+			return fmt.Errorf("decode body of message %s: %w", message.MessageId, err)
+		}
+
+		return handler(ctx, message, body)
+	}
+}
+
+// unwrapSNSEnvelope returns the Message of the SNS notification if the body is one, or the body as-is otherwise.
+func unwrapSNSEnvelope(body string) string {
+	var envelope struct {
+		Type     string
+		TopicArn string
+		Message  *string
+	}
+
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil || envelope.Type != "Notification" || envelope.TopicArn == "" || envelope.Message == nil {
+		return body
+	}
+
+	return *envelope.Message
+}
@@ -0,0 +1,40 @@
+package sqsevent
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nguyengg/golambda/golambdatest"
+	"reflect"
+	"testing"
+)
+
+func TestWrapTypedMessageHandler(t *testing.T) {
+	type Body struct {
+		Name string `json:"name"`
+	}
+
+	var got []string
+	r := golambdatest.NewRecorder()
+	h := WrapTypedMessageHandler(func(ctx context.Context, message events.SQSMessage, body Body) error {
+		got = append(got, body.Name)
+		return nil
+	}, r.Options()...)
+
+	response, err := h(golambdatest.NewContext(context.Background()), events.SQSEvent{Records: []events.SQSMessage{
+		{MessageId: "raw", Body: `{"name":"raw"}`},
+		{MessageId: "sns", Body: `{"Type":"Notification","MessageId":"1","TopicArn":"arn:aws:sns:us-east-1:123456789012:topic","Message":"{\"name\":\"sns\"}"}`},
+		{MessageId: "invalid", Body: `not json`},
+	}})
+	if err != nil {
+		t.Fatalf("WrapTypedMessageHandler() error = %v", err)
+	}
+
+	if want := []string{"raw", "sns"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WrapTypedMessageHandler() got = %v, want %v", got, want)
+	}
+	if want := []events.SQSBatchItemFailure{{ItemIdentifier: "invalid"}}; !reflect.DeepEqual(response.BatchItemFailures, want) {
+		t.Errorf("WrapTypedMessageHandler() failures = %v, want %v", response.BatchItemFailures, want)
+	}
+
+	r.LastMetrics(t).AssertCounter(t, CounterKeyDecodeFailure, 1)
+}
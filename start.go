@@ -6,8 +6,8 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/nguyengg/golambda/configsupport"
 	"github.com/nguyengg/golambda/logsupport"
-	"github.com/nguyengg/golambda/metrics"
 	"github.com/nguyengg/golambda/start"
+	"github.com/nguyengg/golambda/tracing"
 	"log"
 )
 
@@ -17,12 +17,22 @@ import (
 // Use this wrapper if there isn't one created for specific events.
 func StartHandlerFunc[TIn any, TOut any, H lambda.HandlerFunc[TIn, TOut]](handler H, options ...start.Option) {
 	opts := start.New(options)
+	lambda.StartHandlerFunc(wrapHandlerFunc[TIn, TOut](handler, opts), opts.HandlerOptions...)
+}
+
+// WrapHandlerFunc returns the handler that StartHandlerFunc passes to the Lambda runtime, which is the given handler
+// wrapped with metrics, logging, and middlewares according to the options.
+//
+// Use this if you need to invoke the handler without the Lambda runtime, e.g. in tests.
+func WrapHandlerFunc[TIn any, TOut any, H lambda.HandlerFunc[TIn, TOut]](handler H, options ...start.Option) func(context.Context, TIn) (TOut, error) {
+	return wrapHandlerFunc[TIn, TOut](handler, start.New(options))
+}
+
+func wrapHandlerFunc[TIn any, TOut any, H lambda.HandlerFunc[TIn, TOut]](handler H, opts *start.Options) func(context.Context, TIn) (TOut, error) {
+	h := start.Chain(opts, start.Handler[TIn, TOut](handler))
 
-	lambda.StartHandlerFunc(func(ctx context.Context, in TIn) (out TOut, err error) {
-		m := metrics.NewSimpleMetricsContext(
-			opts.LoggerProvider(ctx).WithContext(ctx),
-			"",
-			0)
+	return func(ctx context.Context, in TIn) (out TOut, err error) {
+		m := opts.NewMetrics(ctx, "", 0)
 		ctx = m.WithContext(ctx)
 
 		if !opts.DisableSetUpGlobalLogger {
@@ -49,10 +59,21 @@ func StartHandlerFunc[TIn any, TOut any, H lambda.HandlerFunc[TIn, TOut]](handle
 			}()
 		}
 
+		panicked := true
+
+		ctx, span := opts.StartSpan(ctx, "golambda")
+		defer func() {
+			tracing.End(span, err, panicked)
+		}()
+
 		defer func() {
 			switch r := recover(); {
 			case r != nil:
-				log.Printf("ERROR handler panicked with error: %#v", r)
+				if opts.RecoverPanics {
+					err = opts.Recover(ctx, r)
+				} else {
+					log.Printf("ERROR handler panicked with error: %#v", r)
+				}
 				m.Panicked()
 			case err != nil:
 				log.Printf("ERROR handler failed with error: %#v", err)
@@ -62,6 +83,8 @@ func StartHandlerFunc[TIn any, TOut any, H lambda.HandlerFunc[TIn, TOut]](handle
 			m.Log()
 		}()
 
-		return handler(ctx, in)
-	}, opts.HandlerOptions...)
+		out, err = h(ctx, in)
+		panicked = false
+		return
+	}
 }
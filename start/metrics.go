@@ -0,0 +1,32 @@
+package start
+
+import (
+	"context"
+	"github.com/nguyengg/golambda/metrics"
+)
+
+// NewMetrics creates the metrics.Metrics instance for a new invocation.
+//
+// The logger from LoggerProvider is passed to metrics.NewSimpleMetricsContext so that it receives the request Id. If
+// MetricsOutput is set, the metrics are written there instead of standard error, and if MetricsEMF is set, the metrics
+// are written as CloudWatch Embedded Metric Format documents. If MetricsSink is set, the metrics are passed to it
+// instead.
+func (o *Options) NewMetrics(ctx context.Context, requestId string, startTimeMilliEpoch int64) metrics.Metrics {
+	m := metrics.NewSimpleMetricsContext(o.LoggerProvider(ctx).WithContext(ctx), requestId, startTimeMilliEpoch)
+	if sm, ok := m.(*metrics.SimpleMetrics); ok {
+		if o.MetricsOutput != nil {
+			sm.SetOutput(o.MetricsOutput)
+		}
+		if o.MetricsEMF != nil {
+			sm.SetEMF(o.MetricsEMF)
+		}
+		if o.MetricsSink != nil {
+			sm.SetSink(o.MetricsSink)
+		}
+		if o.MetricsTimingHistograms {
+			sm.SetTimingHistograms(true)
+		}
+	}
+
+	return m
+}
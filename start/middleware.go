@@ -0,0 +1,78 @@
+package start
+
+import (
+	"context"
+	"fmt"
+)
+
+// Handler is the generic form of the handlers that are wrapped by the various Start functions.
+//
+// Handlers that don't return a response (e.g. s3event.Handler) are adapted to use struct{} as TOut.
+type Handler[TIn any, TOut any] func(ctx context.Context, in TIn) (TOut, error)
+
+// Middleware decorates a Handler with cross-cutting concerns such as authentication, tracing, validation, timeouts, etc.
+//
+// Middlewares are invoked after the metrics.Metrics instance has been attached to the context so metrics.Ctx can be
+// used to add additional metrics. Middlewares typed as Middleware[any, any] apply to every event type.
+type Middleware[TIn any, TOut any] func(next Handler[TIn, TOut]) Handler[TIn, TOut]
+
+// WithMiddleware adds middlewares to the chain that will wrap the handler.
+//
+// Middlewares are applied in the order they are given, across multiple WithMiddleware options, with the first one being
+// the outermost. A middleware only applies to wrappers whose event and response types match TIn and TOut, unless it is
+// typed as Middleware[any, any] in which case it applies to all wrappers.
+//
+// Usage:
+//
+//	sqsevent.Start(handler, start.WithMiddleware(func(next start.Handler[events.SQSEvent, events.SQSEventResponse]) start.Handler[events.SQSEvent, events.SQSEventResponse] {
+//		return func(ctx context.Context, request events.SQSEvent) (events.SQSEventResponse, error) {
+//			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+//			defer cancel()
+//			return next(ctx, request)
+//		}
+//	}))
+func WithMiddleware[TIn any, TOut any](middlewares ...Middleware[TIn, TOut]) Option {
+	return func(o *Options) {
+		for _, m := range middlewares {
+			o.Middlewares = append(o.Middlewares, m)
+		}
+	}
+}
+
+// Chain wraps the handler with the middlewares from Options that apply to types TIn and TOut.
+//
+// Middlewares typed as Middleware[any, any] receive the event as TIn and must return either a TOut or nil as the
+// response; any other value will be replaced with the zero-value TOut.
+func Chain[TIn any, TOut any](opts *Options, handler Handler[TIn, TOut]) Handler[TIn, TOut] {
+	for i := len(opts.Middlewares) - 1; i >= 0; i-- {
+		switch m := opts.Middlewares[i].(type) {
+		case Middleware[TIn, TOut]:
+			handler = m(handler)
+		case Middleware[any, any]:
+			handler = adapt(m, handler)
+		}
+	}
+
+	return handler
+}
+
+func adapt[TIn any, TOut any](m Middleware[any, any], next Handler[TIn, TOut]) Handler[TIn, TOut] {
+	h := m(func(ctx context.Context, in any) (any, error) {
+		v, ok := in.(TIn)
+		if !ok && in != nil {
+			var zero TOut
+			return zero, fmt.Errorf("middleware replaced input with incompatible type %T", in)
+		}
+
+		return next(ctx, v)
+	})
+
+	return func(ctx context.Context, in TIn) (out TOut, err error) {
+		v, err := h(ctx, in)
+		if o, ok := v.(TOut); ok {
+			out = o
+		}
+
+		return
+	}
+}
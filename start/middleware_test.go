@@ -0,0 +1,54 @@
+package start
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	typed := func(name string) Middleware[string, string] {
+		return func(next Handler[string, string]) Handler[string, string] {
+			return func(ctx context.Context, in string) (string, error) {
+				calls = append(calls, name)
+				return next(ctx, in+name)
+			}
+		}
+	}
+	untyped := func(next Handler[any, any]) Handler[any, any] {
+		return func(ctx context.Context, in any) (any, error) {
+			calls = append(calls, "any")
+			return next(ctx, in)
+		}
+	}
+	ignored := func(next Handler[int, int]) Handler[int, int] {
+		return func(ctx context.Context, in int) (int, error) {
+			t.Errorf("middleware of mismatched types should not be invoked")
+			return next(ctx, in)
+		}
+	}
+
+	opts := New([]Option{
+		WithMiddleware(typed("a"), typed("b")),
+		WithMiddleware[any, any](untyped),
+		WithMiddleware[int, int](ignored),
+		WithMiddleware(typed("c")),
+		DisableSetUpZeroLogGlobalLevel(),
+	})
+
+	h := Chain(opts, func(ctx context.Context, in string) (string, error) {
+		return in + "!", nil
+	})
+
+	got, err := h(context.Background(), "")
+	if err != nil {
+		t.Errorf("Chain() error = %v", err)
+	}
+	if want := "abc!"; got != want {
+		t.Errorf("Chain() got = %v, want %v", got, want)
+	}
+	if want := []string{"a", "b", "any", "c"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("Chain() calls = %v, want %v", calls, want)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/nguyengg/golambda/logsupport"
+	"github.com/nguyengg/golambda/metrics"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+	"io"
 	"os"
 )
 
@@ -26,6 +29,20 @@ type Options struct {
 	// own service logging.
 	DisableMetricsLogging bool
 
+	// MetricsOutput changes where the metrics are written to, which is os.Stderr by default.
+	MetricsOutput io.Writer
+
+	// MetricsSink changes the metrics to be passed to the given metrics.Sink instead of being written to MetricsOutput.
+	// MetricsOutput and MetricsEMF have no effect if MetricsSink is set.
+	MetricsSink metrics.Sink
+
+	// MetricsEMF changes the metrics to be written as CloudWatch Embedded Metric Format documents. See metrics.EMF.
+	MetricsEMF *metrics.EMF
+
+	// MetricsTimingHistograms enables recording timings in histograms so that their percentiles are also logged. See
+	// metrics.SimpleMetrics.SetTimingHistograms.
+	MetricsTimingHistograms bool
+
 	// DisableSetUpGlobalLogger dictates whether logsupport.SetUpGlobalLogger is called on every request.
 	// logsupport.SetUpGlobalLogger sets up log.Default with reasonable flags as well as adding the request Id as
 	// prefix. You should generally leave this feature enabled if you do a lot of logging with the default log module.
@@ -37,6 +54,24 @@ type Options struct {
 	// environment variable to toggle writing Debug logs.
 	DisableSetUpZeroLogGlobalLevel bool
 
+	// RecoverPanics dictates whether the handler recovers from panics instead of crashing the invocation.
+	//
+	// The panic value and its stack trace are logged with the logger from LoggerProvider, and the panic is converted
+	// into an event-appropriate result: a 500 response for HTTP wrappers, a batch item failure for every record for
+	// wrappers that support partial batch responses, and a returned error (PanicError) for everything else. The
+	// "panicked" counter is still incremented in the metrics.
+	RecoverPanics bool
+
+	// TracerProvider enables tracing with OpenTelemetry if set.
+	//
+	// Every invocation gets its own span whose remote parent is the X-Ray trace header of the invocation, tagged with
+	// the same properties that the metrics record such as "path", "method", "routeKey", and "statusCode". The per-record
+	// handlers such as sqsevent.NewMessageHandler create a child span for every record. See package tracing.
+	TracerProvider trace.TracerProvider
+
+	// Middlewares contains the Middleware instances that wrap the handler. Use WithMiddleware to add to it.
+	Middlewares []interface{}
+
 	// HandlerOptions passes along additional Lambda-runtime-specific options. See lambda.StartWithOptions.
 	HandlerOptions []lambda.Option
 }
@@ -84,6 +119,34 @@ func DisableMetricsLogging() Option {
 	}
 }
 
+// WithMetricsOutput changes where the metrics are written to. See Options.MetricsOutput.
+func WithMetricsOutput(w io.Writer) Option {
+	return func(o *Options) {
+		o.MetricsOutput = w
+	}
+}
+
+// WithMetricsSink changes the metrics to be passed to the given metrics.Sink. See Options.MetricsSink.
+func WithMetricsSink(sink metrics.Sink) Option {
+	return func(o *Options) {
+		o.MetricsSink = sink
+	}
+}
+
+// WithEMF changes the metrics to be written as CloudWatch Embedded Metric Format documents. See Options.MetricsEMF.
+func WithEMF(emf *metrics.EMF) Option {
+	return func(o *Options) {
+		o.MetricsEMF = emf
+	}
+}
+
+// WithTimingHistograms enables recording timings in histograms. See Options.MetricsTimingHistograms.
+func WithTimingHistograms() Option {
+	return func(o *Options) {
+		o.MetricsTimingHistograms = true
+	}
+}
+
 // DisableSetUpGlobalLoggerPerRequest disables calling logsupport.SetUpGlobalLogger on every request.
 func DisableSetUpGlobalLoggerPerRequest() Option {
 	return func(o *Options) {
@@ -98,6 +161,13 @@ func DisableSetUpZeroLogGlobalLevel() Option {
 	}
 }
 
+// RecoverPanics enables recovering from panics. See Options.RecoverPanics.
+func RecoverPanics() Option {
+	return func(o *Options) {
+		o.RecoverPanics = true
+	}
+}
+
 // WithLoggerProvider allows customisation of the logger and its context on every request.
 func WithLoggerProvider(loggerProvider func(ctx context.Context) *zerolog.Logger) Option {
 	return func(o *Options) {
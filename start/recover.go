@@ -0,0 +1,54 @@
+package start
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error created from a recovered panic when Options.RecoverPanics is enabled.
+type PanicError struct {
+	// Value is the value returned by recover.
+	Value interface{}
+	// Stack is the stack trace captured at the time of recovery. See debug.Stack.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("handler panicked: %v", e.Value)
+}
+
+// Unwrap returns the recovered value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// Recover converts the value returned by recover into a PanicError and logs it with the logger from LoggerProvider.
+//
+// Recover must be called from the same deferred function that calls recover so that the captured stack trace still
+// contains the frames that panicked. Returns nil if r is nil.
+//
+// Usage:
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			err = opts.Recover(ctx, r)
+//		}
+//	}()
+func (o *Options) Recover(ctx context.Context, r interface{}) *PanicError {
+	if r == nil {
+		return nil
+	}
+
+	e := &PanicError{Value: r, Stack: debug.Stack()}
+	o.LoggerProvider(ctx).Error().
+		Str("panic", fmt.Sprintf("%v", r)).
+		Str("stack", string(e.Stack)).
+		Msg("recovered from panic")
+
+	return e
+}
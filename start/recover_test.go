@@ -0,0 +1,50 @@
+package start
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/rs/zerolog"
+	"strings"
+	"testing"
+)
+
+func TestOptions_Recover(t *testing.T) {
+	var buf bytes.Buffer
+	opts := New([]Option{
+		RecoverPanics(),
+		DisableSetUpZeroLogGlobalLevel(),
+		WithLoggerProvider(func(ctx context.Context) *zerolog.Logger {
+			l := zerolog.New(&buf)
+			return &l
+		}),
+	})
+
+	cause := errors.New("boom")
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = opts.Recover(context.Background(), r)
+			}
+		}()
+
+		panic(cause)
+	}()
+
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Recover() got %T, want *PanicError", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("Recover() does not unwrap to the panic value")
+	}
+	if !strings.Contains(string(pe.Stack), "TestOptions_Recover") {
+		t.Errorf("Recover() stack does not contain the panicking function: %s", pe.Stack)
+	}
+	if !strings.Contains(buf.String(), `"panic":"boom"`) {
+		t.Errorf("Recover() did not log the panic: %s", buf.String())
+	}
+	if opts.Recover(context.Background(), nil) != nil {
+		t.Errorf("Recover(nil) should return nil")
+	}
+}
@@ -0,0 +1,28 @@
+package start
+
+import (
+	"context"
+	"github.com/nguyengg/golambda/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// WithTracing enables tracing with spans created from the given trace.TracerProvider. See Options.TracerProvider.
+func WithTracing(tp trace.TracerProvider) Option {
+	return func(o *Options) {
+		o.TracerProvider = tp
+	}
+}
+
+// StartSpan starts the span of a new invocation with tracing.StartInvocation if TracerProvider is set.
+//
+// If TracerProvider is not set, the returned span is a no-op and the context is returned as-is. The caller must end the
+// span, usually with tracing.End.
+func (o *Options) StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if o.TracerProvider == nil {
+		return ctx, noop.Span{}
+	}
+
+	return tracing.StartInvocation(ctx, o.TracerProvider, name, attrs...)
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	awsmw "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	smithymw "github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of the spans created by ClientSideTracingMiddleware.
+const (
+	AttributeKeyRPCSystem          = "rpc.system"
+	AttributeKeyRPCService         = "rpc.service"
+	AttributeKeyRPCMethod          = "rpc.method"
+	AttributeKeyAWSRequestId       = "aws.request_id"
+	AttributeKeyHTTPStatusCode     = "http.response.status_code"
+	AttributeKeyAWSRequestAttempts = "aws.request_attempts"
+)
+
+// Default adds a ClientSideTracingMiddleware to the config.
+//
+// Usage:
+//
+//	cfg, err := config.LoadDefaultConfig(context.TODO(), metrics.Default, tracing.Default)
+func Default(o *config.LoadOptions) error {
+	o.APIOptions = append(o.APIOptions, ClientSideTracingMiddleware())
+	return nil
+}
+
+// ClientSideTracingMiddleware creates a new middleware that creates a client span for every AWS SDK call.
+//
+// Usage:
+//
+//	cfg, _ := config.LoadDefaultConfig(ctx)
+//	cfg.APIOptions = append(cfg.APIOptions, metrics.ClientSideMetricsMiddleware(), tracing.ClientSideTracingMiddleware())
+//
+// The span is a child of the span from context, and is named "{serviceId}.{operationName}" (e.g. "DynamoDB.GetItem")
+// to match the keys of metrics.ClientSideMetricsMiddleware. A single span covers all attempts of the call; the number
+// of attempts, the request Id, and the status code of the last attempt are added as attributes. Like StartRecord, the
+// span is a no-op unless tracing has been enabled with start.WithTracing.
+func ClientSideTracingMiddleware() func(stack *smithymw.Stack) error {
+	return func(stack *smithymw.Stack) error {
+		// the Initialize step wraps the retry loop so the span covers every attempt.
+		return stack.Initialize.Add(&clientSideTracingMiddleware{}, smithymw.After)
+	}
+}
+
+// Should implement middleware.InitializeMiddleware.
+type clientSideTracingMiddleware struct {
+}
+
+func (c clientSideTracingMiddleware) ID() string {
+	return "ClientSideTracing"
+}
+
+func (c clientSideTracingMiddleware) HandleInitialize(ctx context.Context, input smithymw.InitializeInput, handler smithymw.InitializeHandler) (smithymw.InitializeOutput, smithymw.Metadata, error) {
+	serviceId := awsmw.GetServiceID(ctx)
+	operationName := awsmw.GetOperationName(ctx)
+
+	ctx, span := trace.SpanFromContext(ctx).
+		TracerProvider().
+		Tracer(InstrumentationName).
+		Start(ctx, serviceId+"."+operationName,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String(AttributeKeyRPCSystem, "aws-api"),
+				attribute.String(AttributeKeyRPCService, serviceId),
+				attribute.String(AttributeKeyRPCMethod, operationName)))
+
+	output, metadata, err := handler.HandleInitialize(ctx, input)
+
+	if requestId, ok := awsmw.GetRequestIDMetadata(metadata); ok {
+		span.SetAttributes(attribute.String(AttributeKeyAWSRequestId, requestId))
+	}
+	if resp, ok := awsmw.GetRawResponse(metadata).(*smithyhttp.Response); ok {
+		span.SetAttributes(attribute.Int(AttributeKeyHTTPStatusCode, resp.StatusCode))
+	}
+	if results, ok := retry.GetAttemptResults(metadata); ok {
+		span.SetAttributes(attribute.Int(AttributeKeyAWSRequestAttempts, len(results.Results)))
+	}
+
+	End(span, err, false)
+
+	return output, metadata, err
+}
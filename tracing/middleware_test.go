@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientSideTracingMiddleware(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.Header().Set("X-Amzn-Requestid", "my-request-id")
+		if attempts++; attempts < 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ThrottlingException","message":"slow down"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := dynamodb.New(dynamodb.Options{
+		Region:       "us-west-2",
+		Credentials:  aws.AnonymousCredentials{},
+		BaseEndpoint: aws.String(server.URL),
+		Retryer: retry.NewStandard(func(o *retry.StandardOptions) {
+			o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+			o.RateLimiter = ratelimit.None
+		}),
+		APIOptions: []func(*middleware.Stack) error{ClientSideTracingMiddleware()},
+	})
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx, span := StartInvocation(context.Background(), tp, "invocation")
+
+	if _, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String("my-table")}); err != nil {
+		t.Fatalf("DescribeTable() error = %v", err)
+	}
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+
+	call := spans[0]
+	if call.Name != "DynamoDB.DescribeTable" {
+		t.Errorf("span Name = %v, want DynamoDB.DescribeTable", call.Name)
+	}
+	if call.SpanKind != trace.SpanKindClient {
+		t.Errorf("span SpanKind = %v, want client", call.SpanKind)
+	}
+	if call.Parent.SpanID() != spans[1].SpanContext.SpanID() {
+		t.Errorf("span parent = %v, want %v", call.Parent.SpanID(), spans[1].SpanContext.SpanID())
+	}
+	if call.Status.Code != codes.Unset {
+		t.Errorf("span Status = %v, want unset", call.Status)
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range call.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	for k, want := range map[attribute.Key]attribute.Value{
+		AttributeKeyRPCSystem:          attribute.StringValue("aws-api"),
+		AttributeKeyRPCService:         attribute.StringValue("DynamoDB"),
+		AttributeKeyRPCMethod:          attribute.StringValue("DescribeTable"),
+		AttributeKeyAWSRequestId:       attribute.StringValue("my-request-id"),
+		AttributeKeyHTTPStatusCode:     attribute.IntValue(200),
+		AttributeKeyAWSRequestAttempts: attribute.IntValue(2),
+	} {
+		if got := attrs[k]; got != want {
+			t.Errorf("span %s = %v, want %v", k, got.Emit(), want.Emit())
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Package tracing integrates the handler wrappers with OpenTelemetry tracing.
+//
+// Tracing is enabled with start.WithTracing, in which case every invocation gets its own span whose parent is the
+// X-Ray trace header of the invocation (if available), and the per-record handlers such as sqsevent.NewMessageHandler
+// create a child span for every record. Use ClientSideTracingMiddleware to create spans for AWS SDK calls as well.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"strings"
+)
+
+// InstrumentationName is the name of the trace.Tracer that creates the spans.
+const InstrumentationName = "github.com/nguyengg/golambda"
+
+// Attribute keys of the invocation span that are not shared with metrics.Metrics properties.
+const (
+	AttributeKeyFunctionName = "faas.name"
+	AttributeKeyInvocationId = "faas.invocation_id"
+	AttributeKeyResourceId   = "cloud.resource_id"
+	AttributeKeyStatusCode   = "statusCode"
+)
+
+// XRayTraceHeader returns the X-Ray trace header of the current invocation.
+//
+// The Lambda runtime passes the header in the context, falling back to the _X_AMZN_TRACE_ID environment variable.
+// Returns empty string if neither is available.
+func XRayTraceHeader(ctx context.Context) string {
+	if v, ok := ctx.Value("x-amzn-trace-id").(string); ok && v != "" {
+		return v
+	}
+
+	return os.Getenv("_X_AMZN_TRACE_ID")
+}
+
+// ParseXRayTraceHeader converts an X-Ray trace header into a remote trace.SpanContext.
+//
+// The header has the form "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1". Returns false
+// if the header does not contain a valid root trace Id and parent segment Id.
+//
+// See https://docs.aws.amazon.com/xray/latest/devguide/xray-concepts.html#xray-concepts-tracingheader.
+func ParseXRayTraceHeader(header string) (sc trace.SpanContext, ok bool) {
+	var (
+		traceId trace.TraceID
+		spanId  trace.SpanID
+		flags   trace.TraceFlags
+	)
+
+	for _, field := range strings.Split(header, ";") {
+		k, v, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch k {
+		case "Root":
+			// 1-{8 hex digits of epoch seconds}-{24 hex digits}
+			parts := strings.Split(v, "-")
+			if len(parts) != 3 || parts[0] != "1" || len(parts[1]) != 8 || len(parts[2]) != 24 {
+				return sc, false
+			}
+			if _, err := hex.Decode(traceId[:], []byte(parts[1]+parts[2])); err != nil {
+				return sc, false
+			}
+		case "Parent":
+			if len(v) != 16 {
+				return sc, false
+			}
+			if _, err := hex.Decode(spanId[:], []byte(v)); err != nil {
+				return sc, false
+			}
+		case "Sampled":
+			if v == "1" {
+				flags = trace.FlagsSampled
+			}
+		}
+	}
+
+	sc = trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceId,
+		SpanID:     spanId,
+		TraceFlags: flags,
+		Remote:     true,
+	})
+	return sc, sc.IsValid()
+}
+
+// StartInvocation starts the span of a Lambda invocation using a trace.Tracer from the given provider.
+//
+// If the context doesn't already have a span, the X-Ray trace header from XRayTraceHeader becomes the remote parent.
+// The span is named after lambdacontext.FunctionName, or the given name if the function name is not available, and has
+// the function name, invocation Id, and function ARN as attributes in addition to the given attributes.
+func StartInvocation(ctx context.Context, tp trace.TracerProvider, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		if sc, ok := ParseXRayTraceHeader(XRayTraceHeader(ctx)); ok {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
+		}
+	}
+
+	if lambdacontext.FunctionName != "" {
+		name = lambdacontext.FunctionName
+		attrs = append(attrs, attribute.String(AttributeKeyFunctionName, lambdacontext.FunctionName))
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		attrs = append(attrs,
+			attribute.String(AttributeKeyInvocationId, lc.AwsRequestID),
+			attribute.String(AttributeKeyResourceId, lc.InvokedFunctionArn))
+	}
+
+	return tp.Tracer(InstrumentationName).Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+}
+
+// StartRecord starts a child span for a single record of a batch such as an SQS message.
+//
+// The span is created from the trace.TracerProvider of the span in the context, so it is a no-op unless tracing has
+// been enabled with start.WithTracing.
+func StartRecord(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return trace.SpanFromContext(ctx).
+		TracerProvider().
+		Tracer(InstrumentationName).
+		Start(ctx, name, trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(attrs...))
+}
+
+// SetStatusCode adds the HTTP status code as an attribute of the span, and marks the span as failed if it's a 5xx.
+func SetStatusCode(span trace.Span, statusCode int) {
+	span.SetAttributes(attribute.Int(AttributeKeyStatusCode, statusCode))
+	if statusCode >= 500 {
+		span.SetStatus(codes.Error, "")
+	}
+}
+
+// End marks the span as failed if the handler returned an error or panicked, then ends it.
+func End(span trace.Span, err error, panicked bool) {
+	switch {
+	case panicked:
+		span.SetStatus(codes.Error, "panicked")
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
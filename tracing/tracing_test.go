@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func TestParseXRayTraceHeader(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		wantTraceId string
+		wantSpanId  string
+		wantSampled bool
+		wantOk      bool
+	}{
+		{
+			name:        "sampled",
+			header:      "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1",
+			wantTraceId: "5759e988bd862e3fe1be46a994272793",
+			wantSpanId:  "53995c3f42cd8ad8",
+			wantSampled: true,
+			wantOk:      true,
+		},
+		{
+			name:        "not sampled with lineage",
+			header:      "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0;Lineage=a87bd80c:1",
+			wantTraceId: "5759e988bd862e3fe1be46a994272793",
+			wantSpanId:  "53995c3f42cd8ad8",
+			wantOk:      true,
+		},
+		{
+			name:   "no parent",
+			header: "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=1",
+		},
+		{
+			name:   "bad root",
+			header: "Root=2-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8",
+		},
+		{
+			name:   "not hex",
+			header: "Root=1-5759e988-bd862e3fe1be46a99427279z;Parent=53995c3f42cd8ad8",
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, ok := ParseXRayTraceHeader(tt.header)
+			if ok != tt.wantOk {
+				t.Fatalf("ParseXRayTraceHeader() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+
+			if got := sc.TraceID().String(); got != tt.wantTraceId {
+				t.Errorf("ParseXRayTraceHeader() TraceID = %v, want %v", got, tt.wantTraceId)
+			}
+			if got := sc.SpanID().String(); got != tt.wantSpanId {
+				t.Errorf("ParseXRayTraceHeader() SpanID = %v, want %v", got, tt.wantSpanId)
+			}
+			if got := sc.IsSampled(); got != tt.wantSampled {
+				t.Errorf("ParseXRayTraceHeader() IsSampled = %v, want %v", got, tt.wantSampled)
+			}
+			if !sc.IsRemote() {
+				t.Errorf("ParseXRayTraceHeader() IsRemote = false, want true")
+			}
+		})
+	}
+}
+
+func TestStartInvocation(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	ctx := context.WithValue(context.Background(), "x-amzn-trace-id", "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1")
+	ctx = lambdacontext.NewContext(ctx, &lambdacontext.LambdaContext{AwsRequestID: "my-request-id"})
+
+	ctx, span := StartInvocation(ctx, tp, "invocation", attribute.String("path", "/"))
+	_, child := StartRecord(ctx, "record")
+	End(child, errors.New("fail"), false)
+	SetStatusCode(span, 502)
+	End(span, nil, false)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+
+	record, invocation := spans[0], spans[1]
+	if got := invocation.Parent.TraceID().String(); got != "5759e988bd862e3fe1be46a994272793" {
+		t.Errorf("invocation parent TraceID = %v, want X-Ray root", got)
+	}
+	if got := invocation.Parent.SpanID().String(); got != "53995c3f42cd8ad8" {
+		t.Errorf("invocation parent SpanID = %v, want X-Ray parent", got)
+	}
+	if invocation.SpanKind != trace.SpanKindServer {
+		t.Errorf("invocation SpanKind = %v, want server", invocation.SpanKind)
+	}
+	if invocation.Status.Code != codes.Error {
+		t.Errorf("invocation Status = %v, want error because of 502", invocation.Status)
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range invocation.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if got := attrs["path"].AsString(); got != "/" {
+		t.Errorf("invocation path = %v, want /", got)
+	}
+	if got := attrs[AttributeKeyInvocationId].AsString(); got != "my-request-id" {
+		t.Errorf("invocation %s = %v, want my-request-id", AttributeKeyInvocationId, got)
+	}
+	if got := attrs[AttributeKeyStatusCode].AsInt64(); got != 502 {
+		t.Errorf("invocation %s = %v, want 502", AttributeKeyStatusCode, got)
+	}
+
+	if record.Parent.SpanID() != invocation.SpanContext.SpanID() {
+		t.Errorf("record parent SpanID = %v, want %v", record.Parent.SpanID(), invocation.SpanContext.SpanID())
+	}
+	if record.SpanKind != trace.SpanKindConsumer {
+		t.Errorf("record SpanKind = %v, want consumer", record.SpanKind)
+	}
+	if record.Status.Code != codes.Error || record.Status.Description != "fail" {
+		t.Errorf("record Status = %v, want error fail", record.Status)
+	}
+}
+
+func TestStartRecord_withoutTracing(t *testing.T) {
+	ctx, span := StartRecord(context.Background(), "record")
+	End(span, nil, false)
+
+	if span.SpanContext().IsValid() || trace.SpanContextFromContext(ctx).IsValid() {
+		t.Errorf("StartRecord() creates a valid span without tracing")
+	}
+}